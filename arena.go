@@ -0,0 +1,42 @@
+package suffix
+
+// Keys not shorter than this are allocated separately, so a large key doesn't waste the
+// rest of a chunk.
+const (
+	arenaChunkSize  = 4096
+	arenaMaxKeySize = arenaChunkSize / 8
+)
+
+// _Arena copies the keys owned by tree into large chunks, so inserting many short keys
+// doesn't create many small objects. A chunk is freed after all the keys in it are removed.
+// An _Arena must not be shared by trees which insert keys independently, like the different
+// versions of ImmutableTree.
+type _Arena struct {
+	chunk []byte
+}
+
+// Return a copy of key allocated in arena. The capacity of the copy is clipped, so appending
+// to it never overwrites the next key.
+func copyKey[K _Key](arena *_Arena, key K) []byte {
+	keyLen := len(key)
+	if keyLen == 0 {
+		return []byte{}
+	}
+	if keyLen >= arenaMaxKeySize {
+		return append(make([]byte, 0, keyLen), key...)
+	}
+	if cap(arena.chunk)-len(arena.chunk) < keyLen {
+		arena.chunk = make([]byte, 0, arenaChunkSize)
+	}
+	start := len(arena.chunk)
+	arena.chunk = append(arena.chunk, key...)
+	return arena.chunk[start:len(arena.chunk):len(arena.chunk)]
+}
+
+// Give back the space of key if it is the last one copied and is not used.
+func (arena *_Arena) undo(key []byte) {
+	start := len(arena.chunk) - len(key)
+	if len(key) > 0 && start >= 0 && &arena.chunk[start] == &key[0] {
+		arena.chunk = arena.chunk[:start]
+	}
+}
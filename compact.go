@@ -0,0 +1,149 @@
+package suffix
+
+// The compact layout has no pointer except the slices in CompactTree itself, so the GC
+// doesn't need to scan the nodes, edges and keys. Labels are not stored separately, they
+// refer to the keys in the arena. Nodes and leaves are addressed by their index.
+const compactLeafFlag = 1 << 31
+
+type _CompactNode struct {
+	firstEdge uint32
+	edgesNum  uint32
+}
+
+type _CompactEdge struct {
+	labelOff uint32
+	labelLen uint32
+	// Index of node, or index of leaf with compactLeafFlag
+	point uint32
+}
+
+type _CompactLeaf struct {
+	keyOff uint32
+	keyLen uint32
+}
+
+// CompactTree is a read-only suffix tree in the compact layout created by Tree.Compact.
+// Compared with Tree, it is smaller and puts much less pressure on the GC, as it holds
+// a few large slices instead of millions of pointers.
+// The returned keys refer to the underlying arena, so they should not be modified.
+// It is safe to read a CompactTree concurrently.
+type CompactTree[V any] struct {
+	arena []byte
+	// The nodes are in post order, so root is the last one
+	root   uint32
+	nodes  []_CompactNode
+	edges  []_CompactEdge
+	leaves []_CompactLeaf
+	// The values are stored separately, so the leaves are pointer-free even if V has pointers
+	values []V
+}
+
+type _Compactor[V any] struct {
+	tree *CompactTree[V]
+}
+
+func (c *_Compactor[V]) leaf(leaf *_Leaf[V]) (point uint32, keyOff uint32) {
+	tree := c.tree
+	keyOff = uint32(len(tree.arena))
+	tree.arena = append(tree.arena, leaf.originKey...)
+	tree.leaves = append(tree.leaves, _CompactLeaf{
+		keyOff: keyOff,
+		keyLen: uint32(len(leaf.originKey)),
+	})
+	tree.values = append(tree.values, leaf.value)
+	return uint32(len(tree.leaves) - 1), keyOff
+}
+
+func (c *_Compactor[V]) node(edges []_FlatEdge) uint32 {
+	tree := c.tree
+	tree.nodes = append(tree.nodes, _CompactNode{
+		firstEdge: uint32(len(tree.edges)),
+		edgesNum:  uint32(len(edges)),
+	})
+	for _, edge := range edges {
+		point := edge.point
+		if edge.isLeaf {
+			point |= compactLeafFlag
+		}
+		tree.edges = append(tree.edges, _CompactEdge{
+			labelOff: edge.labelOff,
+			labelLen: edge.labelLen,
+			point:    point,
+		})
+	}
+	return uint32(len(tree.nodes) - 1)
+}
+
+// Compact copies the tree into a CompactTree. The tree itself is not changed.
+// The total length of keys should be less than 4 GiB.
+func (tree *Tree[V]) Compact() *CompactTree[V] {
+	arenaSize := 0
+	stop := false
+	tree.root.walk(func(key []byte, _ V) bool {
+		arenaSize += len(key)
+		return false
+	}, &stop)
+	c := &_Compactor[V]{
+		tree: &CompactTree[V]{
+			arena:  make([]byte, 0, arenaSize),
+			leaves: make([]_CompactLeaf, 0, tree.leavesNum),
+			values: make([]V, 0, tree.leavesNum),
+		},
+	}
+	c.tree.root = flatWrite[V](c, tree.root)
+	// Drop the extra capacity of nodes and edges
+	c.tree.nodes = append([]_CompactNode(nil), c.tree.nodes...)
+	c.tree.edges = append([]_CompactEdge(nil), c.tree.edges...)
+	return c.tree
+}
+
+func (tree *CompactTree[V]) edgesNum(node uint32) int {
+	return int(tree.nodes[node].edgesNum)
+}
+
+func (tree *CompactTree[V]) edge(node uint32, i int) (label []byte, point uint32, isLeaf bool) {
+	edge := &tree.edges[tree.nodes[node].firstEdge+uint32(i)]
+	end := edge.labelOff + edge.labelLen
+	return tree.arena[edge.labelOff:end:end], edge.point &^ compactLeafFlag,
+		edge.point&compactLeafFlag != 0
+}
+
+func (tree *CompactTree[V]) leaf(leaf uint32) (key []byte, value V) {
+	l := &tree.leaves[leaf]
+	end := l.keyOff + l.keyLen
+	return tree.arena[l.keyOff:end:end], tree.values[leaf]
+}
+
+// Len returns the number of keys.
+func (tree *CompactTree[V]) Len() int {
+	return len(tree.leaves)
+}
+
+// Get returns the value of given key and a boolean to indicate
+// whether the value is found.
+func (tree *CompactTree[V]) Get(key []byte) (value V, found bool) {
+	if key == nil {
+		return value, false
+	}
+	return flatGet[V](tree, tree.root, key)
+}
+
+// LongestSuffix returns the key which is the longest suffix of the given key,
+// and the value referred by this key. See Tree.LongestSuffix.
+func (tree *CompactTree[V]) LongestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	if key == nil {
+		return nil, value, false
+	}
+	return flatLongestSuffix[V](tree, tree.root, key)
+}
+
+// Walk through the tree, call function with key and value. See Tree.Walk.
+func (tree *CompactTree[V]) Walk(f func(key []byte, value V) bool) {
+	flatWalk[V](tree, tree.root, f)
+}
+
+// WalkSuffix travels through nodes which have given suffix, calls function with key and
+// value. See Tree.WalkSuffix.
+func (tree *CompactTree[V]) WalkSuffix(suffix []byte, f func(key []byte, value V) bool) {
+	flatWalkSuffix[V](tree, tree.root, suffix, f)
+}
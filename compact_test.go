@@ -0,0 +1,155 @@
+package suffix
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertSameAsCompact(t *testing.T, tree *Tree[string], compact *CompactTree[string],
+	queries []string) {
+
+	assert.Equal(t, tree.Len(), compact.Len())
+
+	walked := []string{}
+	tree.Walk(func(key []byte, value string) bool {
+		walked = append(walked, string(key))
+		return false
+	})
+	compactWalked := []string{}
+	compact.Walk(func(key []byte, value string) bool {
+		assert.Equal(t, string(key), value)
+		compactWalked = append(compactWalked, string(key))
+		return false
+	})
+	assert.Equal(t, walked, compactWalked)
+
+	for _, query := range queries {
+		value, found := tree.Get([]byte(query))
+		compactValue, compactFound := compact.Get([]byte(query))
+		assert.Equal(t, found, compactFound, "Get %s", query)
+		assert.Equal(t, value, compactValue, "Get %s", query)
+
+		matchedKey, value, found := tree.LongestSuffix([]byte(query))
+		compactMatchedKey, compactValue, compactFound := compact.LongestSuffix([]byte(query))
+		assert.Equal(t, found, compactFound, "LongestSuffix %s", query)
+		assert.Equal(t, string(matchedKey), string(compactMatchedKey), "LongestSuffix %s", query)
+		assert.Equal(t, value, compactValue, "LongestSuffix %s", query)
+
+		walked = []string{}
+		tree.WalkSuffix([]byte(query), func(key []byte, value string) bool {
+			walked = append(walked, string(key))
+			return false
+		})
+		compactWalked = []string{}
+		compact.WalkSuffix([]byte(query), func(key []byte, value string) bool {
+			compactWalked = append(compactWalked, string(key))
+			return false
+		})
+		assert.Equal(t, walked, compactWalked, "WalkSuffix %s", query)
+	}
+}
+
+func TestCompactTree_Base(t *testing.T) {
+	tree := New[string]()
+	assertSameAsCompact(t, tree, tree.Compact(), []string{"", "able"})
+
+	lists := []string{
+		"edible", "presentable", "abominable", "credible",
+		"picturesque", "statuesque", "nothing", "something", "thing", "nonsense",
+		"random word", "word", "table", "unbelievable", "believable", "sense", "",
+	}
+	for _, s := range lists {
+		tree.Insert([]byte(s), s)
+	}
+	compact := tree.Compact()
+	queries := append(lists, "unpresentable", "able", "ble", "x", "incredible", "esque", "ing")
+	assertSameAsCompact(t, tree, compact, queries)
+
+	_, found := compact.Get(nil)
+	assert.False(t, found)
+	_, _, found = compact.LongestSuffix(nil)
+	assert.False(t, found)
+
+	// The compact tree is a copy
+	tree.Remove([]byte("table"))
+	value, found := compact.Get([]byte("table"))
+	assert.True(t, found)
+	assert.Equal(t, "table", value)
+}
+
+func TestCompactTree_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	tree := New[string]()
+	queries := []string{}
+	for i := 0; i < 1024; i++ {
+		b := randomKey(r, letters, 0, 9)
+		queries = append(queries, string(b))
+		if i%2 == 0 {
+			tree.Insert(b, string(b))
+		}
+	}
+	assertSameAsCompact(t, tree, tree.Compact(), queries)
+}
+
+const benchmarkKeysNum = 500000
+
+func buildBenchmarkTree() *Tree[int] {
+	tree := New[int]()
+	for i := 0; i < benchmarkKeysNum; i++ {
+		tree.Insert([]byte(fmt.Sprintf("%d.host.example.com", i)), i)
+	}
+	return tree
+}
+
+// Report the time of a full GC as ns/op, and the heap size occupied by the tree.
+func benchmarkGC(b *testing.B, build func() interface{}) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	tree := build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc), "heap-bytes")
+	runtime.KeepAlive(tree)
+}
+
+func BenchmarkGC_Tree(b *testing.B) {
+	benchmarkGC(b, func() interface{} {
+		return buildBenchmarkTree()
+	})
+}
+
+func BenchmarkGC_CompactTree(b *testing.B) {
+	benchmarkGC(b, func() interface{} {
+		return buildBenchmarkTree().Compact()
+	})
+}
+
+func BenchmarkLongestSuffix_Tree(b *testing.B) {
+	tree := buildBenchmarkTree()
+	key := []byte("www.12345.host.example.com")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.LongestSuffix(key)
+	}
+}
+
+func BenchmarkLongestSuffix_CompactTree(b *testing.B) {
+	tree := buildBenchmarkTree().Compact()
+	key := []byte("www.12345.host.example.com")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.LongestSuffix(key)
+	}
+}
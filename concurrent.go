@@ -0,0 +1,143 @@
+package suffix
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentTree is a suffix tree which is safe for concurrent use.
+// Readers never take a lock: each read loads the latest snapshot, which is an ImmutableTree,
+// and works on it. Writers are serialized by a mutex, and publish a new snapshot atomically
+// once the mutation is done, so readers never observe half-applied state.
+type ConcurrentTree[V any] struct {
+	lock     sync.Mutex
+	snapshot atomic.Pointer[ImmutableTree[V]]
+}
+
+// NewConcurrentTree creates an empty ConcurrentTree.
+func NewConcurrentTree[V any]() *ConcurrentTree[V] {
+	tree := &ConcurrentTree[V]{}
+	tree.snapshot.Store(NewImmutableTree[V]())
+	return tree
+}
+
+// Snapshot returns the current content of the tree. The snapshot won't be affected by
+// the following mutations.
+func (tree *ConcurrentTree[V]) Snapshot() *ImmutableTree[V] {
+	return tree.snapshot.Load()
+}
+
+// Insert suffix tree with given key and value. Return the previous value and a boolean to
+// indicate whether the insertion is successful.
+func (tree *ConcurrentTree[V]) Insert(key []byte, value V) (oldValue V, ok bool) {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	newTree, oldValue, ok := tree.snapshot.Load().Insert(key, value)
+	tree.snapshot.Store(newTree)
+	return oldValue, ok
+}
+
+// Remove returns the value of given key and a boolean to indicate
+// whethe the value is found. Then the value will be removed.
+func (tree *ConcurrentTree[V]) Remove(key []byte) (oldValue V, found bool) {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	newTree, oldValue, found := tree.snapshot.Load().Remove(key)
+	tree.snapshot.Store(newTree)
+	return oldValue, found
+}
+
+// RemoveSuffix removes all the keys which have given suffix, and returns the number of
+// removed keys.
+func (tree *ConcurrentTree[V]) RemoveSuffix(suffix []byte) (removed int) {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	newTree, removed := tree.snapshot.Load().RemoveSuffix(suffix)
+	tree.snapshot.Store(newTree)
+	return removed
+}
+
+// Update the value of key with the value decided by f. See Tree.Update.
+func (tree *ConcurrentTree[V]) Update(key []byte, f func(oldValue V, exists bool) (newValue V, keep bool)) (
+	value V, exists bool) {
+
+	tree.Batch(func(txn *Txn[V]) {
+		value, exists = txn.Update(key, f)
+	})
+	return value, exists
+}
+
+// Batch applies all the mutations done by f in a transaction, and publishes them at once.
+// The transaction should not be used after f returns.
+func (tree *ConcurrentTree[V]) Batch(f func(txn *Txn[V])) {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	txn := tree.snapshot.Load().Txn()
+	f(txn)
+	tree.snapshot.Store(txn.Commit())
+}
+
+// Get returns the value of given key and a boolean to indicate
+// whether the value is found.
+func (tree *ConcurrentTree[V]) Get(key []byte) (value V, found bool) {
+	return tree.snapshot.Load().Get(key)
+}
+
+// LongestSuffix returns the key which is the longest suffix of the given key,
+// and the value referred by this key. See Tree.LongestSuffix.
+func (tree *ConcurrentTree[V]) LongestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	return tree.snapshot.Load().LongestSuffix(key)
+}
+
+// ShortestSuffix returns the key which is the shortest suffix of the given key,
+// and the value referred by this key. See Tree.ShortestSuffix.
+func (tree *ConcurrentTree[V]) ShortestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	return tree.snapshot.Load().ShortestSuffix(key)
+}
+
+// Len returns the number of keys.
+func (tree *ConcurrentTree[V]) Len() int {
+	return tree.snapshot.Load().Len()
+}
+
+// CountSuffix returns the number of keys which have given suffix in the current snapshot.
+// See Tree.CountSuffix.
+func (tree *ConcurrentTree[V]) CountSuffix(suffix []byte) int {
+	return tree.snapshot.Load().CountSuffix(suffix)
+}
+
+// Walk through the current snapshot of the tree. See Tree.Walk.
+func (tree *ConcurrentTree[V]) Walk(f func(key []byte, value V) bool) {
+	tree.snapshot.Load().Walk(f)
+}
+
+// WalkSuffix travels through nodes which have given suffix in the current snapshot of the tree.
+// See Tree.WalkSuffix.
+func (tree *ConcurrentTree[V]) WalkSuffix(suffix []byte, f func(key []byte, value V) bool) {
+	tree.snapshot.Load().WalkSuffix(suffix, f)
+}
+
+// WalkSuffixMatches calls function with every key which is a suffix of the given key
+// in the current snapshot of the tree. See Tree.WalkSuffixMatches.
+func (tree *ConcurrentTree[V]) WalkSuffixMatches(key []byte, order MatchOrder,
+	f func(matchedKey []byte, value V) bool) {
+
+	tree.snapshot.Load().WalkSuffixMatches(key, order, f)
+}
+
+// All returns an iterator over all keys and values in the snapshot taken when
+// the iteration starts. See Tree.All.
+func (tree *ConcurrentTree[V]) All() iter.Seq2[[]byte, V] {
+	return func(yield func(key []byte, value V) bool) {
+		tree.snapshot.Load().All()(yield)
+	}
+}
+
+// WithSuffix returns an iterator over keys which have given suffix and their values,
+// in the snapshot taken when the iteration starts. See Tree.WithSuffix.
+func (tree *ConcurrentTree[V]) WithSuffix(suffix []byte) iter.Seq2[[]byte, V] {
+	return func(yield func(key []byte, value V) bool) {
+		tree.snapshot.Load().WithSuffix(suffix)(yield)
+	}
+}
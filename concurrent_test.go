@@ -0,0 +1,135 @@
+package suffix
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Run these tests with `go test -race` to detect data race.
+
+func TestConcurrentTree_Base(t *testing.T) {
+	tree := NewConcurrentTree[string]()
+	_, ok := tree.Insert([]byte("able"), "able")
+	assert.True(t, ok)
+	tree.Insert([]byte("table"), "table")
+	snapshot := tree.Snapshot()
+	oldValue, found := tree.Remove([]byte("able"))
+	assert.True(t, found)
+	assert.Equal(t, "able", oldValue)
+	assert.Equal(t, 1, tree.Len())
+	assert.Equal(t, 2, snapshot.Len())
+
+	tree.Batch(func(txn *Txn[string]) {
+		txn.Insert([]byte("presentable"), "presentable")
+		txn.Insert([]byte("word"), "word")
+	})
+	assert.Equal(t, 3, tree.Len())
+	value, found := tree.Get([]byte("word"))
+	assert.True(t, found)
+	assert.Equal(t, "word", value)
+	value, exists := tree.Update([]byte("word"), func(oldValue string, exists bool) (string, bool) {
+		return oldValue + "s", exists
+	})
+	assert.True(t, exists)
+	assert.Equal(t, "words", value)
+	_, exists = tree.Update([]byte("words"), func(oldValue string, exists bool) (string, bool) {
+		return oldValue, false
+	})
+	assert.False(t, exists)
+	assert.Equal(t, 3, tree.Len())
+	matchedKey, _, _ := tree.LongestSuffix([]byte("unpresentable"))
+	assert.Equal(t, "presentable", string(matchedKey))
+	matchedKey, _, _ = tree.ShortestSuffix([]byte("unpresentable"))
+	assert.Equal(t, "table", string(matchedKey))
+
+	keys := []string{}
+	for key := range tree.WithSuffix([]byte("able")) {
+		keys = append(keys, string(key))
+	}
+	assert.Equal(t, []string{"table", "presentable"}, keys)
+	assert.Equal(t, 2, tree.CountSuffix([]byte("able")))
+
+	snapshot = tree.Snapshot()
+	assert.Equal(t, 2, tree.RemoveSuffix([]byte("able")))
+	assert.Equal(t, 1, tree.Len())
+	assert.Equal(t, 3, snapshot.Len())
+}
+
+func TestConcurrentTree_ReadWrite(t *testing.T) {
+	tree := NewConcurrentTree[int]()
+	const writers = 4
+	const keysPerWriter = 256
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				tree.Get([]byte("0-key-1"))
+				tree.LongestSuffix([]byte("prefix.1-key-2"))
+				count := 0
+				tree.WalkSuffix([]byte("-key-1"), func(key []byte, value int) bool {
+					if !bytes.HasSuffix(key, []byte("-key-1")) {
+						t.Errorf("unexpected key %s", key)
+					}
+					count++
+					return false
+				})
+				for key, value := range tree.All() {
+					if value < 0 || len(key) == 0 {
+						t.Errorf("unexpected key %s", key)
+					}
+				}
+				tree.WalkSuffixMatches([]byte("a.3-key-5"), LongestFirst,
+					func(matchedKey []byte, value int) bool {
+						return false
+					})
+			}
+		}()
+	}
+
+	var writerWg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		writerWg.Add(1)
+		go func(i int) {
+			defer writerWg.Done()
+			for j := 0; j < keysPerWriter; j++ {
+				key := []byte(fmt.Sprintf("%d-key-%d", i, j))
+				tree.Insert(key, j)
+				if j%4 == 0 {
+					tree.Remove(key)
+				}
+			}
+			tree.Batch(func(txn *Txn[int]) {
+				for j := 0; j < keysPerWriter; j += 8 {
+					txn.Insert([]byte(fmt.Sprintf("%d-key-%d", i, j)), j)
+				}
+			})
+		}(i)
+	}
+	writerWg.Wait()
+	close(stop)
+	wg.Wait()
+
+	assert.Equal(t, writers*(keysPerWriter*3/4+keysPerWriter/8), tree.Len())
+	for i := 0; i < writers; i++ {
+		for j := 0; j < keysPerWriter; j++ {
+			value, found := tree.Get([]byte(fmt.Sprintf("%d-key-%d", i, j)))
+			assert.Equal(t, j%4 != 0 || j%8 == 0, found)
+			if found {
+				assert.Equal(t, j, value)
+			}
+		}
+	}
+}
@@ -2,8 +2,23 @@ package suffix
 
 import (
 	"fmt"
+	"strings"
 )
 
+func ExampleNew() {
+	tree := New[*string]()
+	for _, s := range []string{"able", "table"} {
+		s := s
+		tree.Insert([]byte(s), &s)
+	}
+	value, found := tree.Get([]byte("table"))
+	if found {
+		// No type assertion is required
+		fmt.Println(*value)
+	}
+	// Output: table
+}
+
 func ExampleTree_Insert() {
 	tree := NewTree()
 	tree.Insert([]byte("sth"), "sth")
@@ -21,6 +36,77 @@ func ExampleTree_Insert() {
 	// else
 }
 
+func ExampleTree_LongestSuffixString() {
+	tree := New[string]()
+	tree.InsertString("example.com", "example")
+	tree.InsertString("cdn.example.com", "cdn")
+	matchedKey, value, found := tree.LongestSuffixString("img.cdn.example.com")
+	if found {
+		fmt.Println(matchedKey, value)
+	}
+	// Output: cdn.example.com cdn
+}
+
+func ExampleTree_CountSuffix() {
+	tree := New[int]()
+	for i, host := range []string{"a.cdn.example.net", "b.cdn.example.net", "example.net"} {
+		tree.InsertString(host, i)
+	}
+	fmt.Println(tree.CountSuffix([]byte(".cdn.example.net")))
+	fmt.Println(tree.CountSuffix([]byte("example.net")))
+	// Output:
+	// 2
+	// 3
+}
+
+func ExampleTree_RemoveSuffixFunc() {
+	tree := New[int]()
+	for i, host := range []string{"a.example.net", "img.example.net", "example.org"} {
+		tree.InsertString(host, i)
+	}
+	removed := tree.RemoveSuffixFunc([]byte(".example.net"), func(key []byte, value int) {
+		fmt.Println("revoke", string(key))
+	})
+	fmt.Println(removed, tree.Len())
+	// Output:
+	// revoke a.example.net
+	// revoke img.example.net
+	// 2 1
+}
+
+func ExampleTree_Update() {
+	tree := New[int]()
+	for _, word := range strings.Fields("to be or not to be") {
+		tree.Update([]byte(word), func(count int, exists bool) (int, bool) {
+			return count + 1, true
+		})
+	}
+	count, _ := tree.Get([]byte("be"))
+	fmt.Println(count)
+	// Output: 2
+}
+
+func ExampleTree_Put() {
+	tree := New[*string]()
+	tree.Put([]byte("sth"), nil)
+	oldValue, replaced := tree.Put([]byte("sth"), nil)
+	// The old value is nil, but it is still a replacement
+	fmt.Println(oldValue == nil, replaced, tree.Len())
+	// Output: true true 1
+}
+
+func ExampleTree_FindAll() {
+	tree := New[string]()
+	tree.InsertString("error", "ERROR")
+	tree.InsertString("timeout", "TIMEOUT")
+	for _, match := range tree.FindAll([]byte("upstream timeout, error 504"), LeftmostLongest) {
+		fmt.Println(match.Start, match.End, match.Value)
+	}
+	// Output:
+	// 9 16 TIMEOUT
+	// 18 23 ERROR
+}
+
 func ExampleTree_Get() {
 	tree := NewTree()
 	tree.Insert([]byte("sth"), "sth")
@@ -46,6 +132,21 @@ func ExampleTree_LongestSuffix() {
 	// table
 }
 
+func ExampleTree_ShortestSuffix() {
+	tree := NewTree()
+	tree.Insert([]byte("table"), "table")
+	tree.Insert([]byte("able"), "able")
+	tree.Insert([]byte("present"), "present")
+	key, value, found := tree.ShortestSuffix([]byte("presentable"))
+	if found {
+		fmt.Println("Matched key:", string(key))
+		fmt.Println(value.(string))
+	}
+	// Output:
+	// Matched key: able
+	// able
+}
+
 func ExampleTree_Remove() {
 	tree := NewTree()
 	tree.Insert([]byte("sth"), "sth")
@@ -105,3 +206,154 @@ func ExampleTree_WalkSuffix() {
 	// Walk and stop in the middle:
 	// table
 }
+
+func ExampleTree_All() {
+	tree := New[int]()
+	tree.Insert([]byte("able"), 1)
+	tree.Insert([]byte("table"), 2)
+	tree.Insert([]byte("presentable"), 3)
+	for key, value := range tree.All() {
+		fmt.Println(string(key), value)
+	}
+	// Output:
+	// able 1
+	// table 2
+	// presentable 3
+}
+
+func ExampleTree_WithSuffix() {
+	tree := New[int]()
+	tree.Insert([]byte("able"), 1)
+	tree.Insert([]byte("table"), 2)
+	tree.Insert([]byte("presentable"), 3)
+	tree.Insert([]byte("present"), 4)
+	for key := range tree.WithSuffix([]byte("table")) {
+		fmt.Println(string(key))
+		if string(key) == "table" {
+			break
+		}
+	}
+	// Output:
+	// table
+}
+
+func ExampleTree_AllSuffixMatches() {
+	tree := New[int]()
+	tree.Insert([]byte("com"), 1)
+	tree.Insert([]byte("example.com"), 2)
+	tree.Insert([]byte("b.example.com"), 3)
+	tree.Insert([]byte("c.example.com"), 4)
+	for key, value := range tree.AllSuffixMatches([]byte("a.b.example.com"), LongestFirst) {
+		fmt.Println(string(key), value)
+	}
+	// Output:
+	// b.example.com 3
+	// example.com 2
+	// com 1
+}
+
+func ExampleNewDomainTree() {
+	tree := NewDomainTree[bool]()
+	tree.Insert([]byte("example.com"), true)
+	_, _, found := tree.LongestSuffix([]byte("www.example.com"))
+	fmt.Println(found)
+	_, _, found = tree.LongestSuffix([]byte("notexample.com"))
+	fmt.Println(found)
+	// Output:
+	// true
+	// false
+}
+
+func ExampleRuleTree_Match() {
+	rt := NewRuleTree[string]()
+	rt.Insert([]byte("*.ck"), "wildcard")
+	rt.Insert([]byte("!www.ck"), "exception")
+	for _, domain := range []string{"a.b.ck", "www.ck"} {
+		match, found := rt.Match([]byte(domain))
+		if found {
+			fmt.Println(string(match.Rule), match.Value, string(match.Matched))
+		}
+	}
+	// Output:
+	// *.ck wildcard b.ck
+	// !www.ck exception www.ck
+}
+
+func ExamplePublicSuffixList_EffectiveTLDPlusOne() {
+	list, err := ParsePublicSuffixList(strings.NewReader(`
+// ===BEGIN ICANN DOMAINS===
+uk
+co.uk
+// ===END ICANN DOMAINS===
+`))
+	if err != nil {
+		panic(err)
+	}
+	publicSuffix, icann := list.PublicSuffix([]byte("www.example.co.uk"))
+	fmt.Println(string(publicSuffix), icann)
+	etldPlusOne, _ := list.EffectiveTLDPlusOne([]byte("www.example.co.uk"))
+	fmt.Println(string(etldPlusOne))
+	// Output:
+	// co.uk true
+	// example.co.uk
+}
+
+func ExampleImmutableTree_Insert() {
+	v1 := NewImmutableTree[int]()
+	v1, _, _ = v1.Insert([]byte("able"), 1)
+	v2, _, _ := v1.Insert([]byte("table"), 2)
+	fmt.Println(v1.Len(), v2.Len())
+	// Output: 1 2
+}
+
+func ExampleImmutableTree_Txn() {
+	tree := NewImmutableTree[int]()
+	txn := tree.Txn()
+	txn.Insert([]byte("able"), 1)
+	txn.Insert([]byte("table"), 2)
+	newTree := txn.Commit()
+	fmt.Println(tree.Len(), newTree.Len())
+	// Output: 0 2
+}
+
+func ExampleTree_MarshalBinary() {
+	tree := New[string]()
+	tree.Insert([]byte("able"), "able")
+	tree.Insert([]byte("table"), "table")
+	data, err := tree.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	newTree := New[string]()
+	if err := newTree.UnmarshalBinary(data); err != nil {
+		panic(err)
+	}
+	key, value, _ := newTree.LongestSuffix([]byte("presentable"))
+	fmt.Println(string(key), value)
+	// Output: table table
+}
+
+func ExampleSubstringIndex() {
+	idx := NewSubstringIndex()
+	idx.Add([]byte("GET /static/app.js"))
+	idx.Add([]byte("GET /api/static"))
+	for _, occurrence := range idx.Occurrences([]byte("static")) {
+		fmt.Println(occurrence.Key, occurrence.Offset)
+	}
+	// Output:
+	// 0 5
+	// 1 9
+}
+
+func ExampleSuffixArray() {
+	arr := NewSuffixArrayFromKeys([][]byte{
+		[]byte("GET /static/app.js"),
+		[]byte("GET /api/static"),
+	})
+	for _, occurrence := range arr.Lookup([]byte("static")) {
+		fmt.Println(occurrence.Key, occurrence.Offset)
+	}
+	// Output:
+	// 0 5
+	// 1 9
+}
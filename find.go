@@ -0,0 +1,106 @@
+package suffix
+
+// FindMode specifies which matches are reported by WalkMatches and FindAll.
+type FindMode int
+
+const (
+	// LeftmostLongest reports the matches which don't overlap. Scanning from the beginning
+	// of text, the match which starts first is chosen, or the longest one if several matches
+	// start at the same position. Then the scanning continues after the chosen match.
+	LeftmostLongest FindMode = iota
+	// AllOverlaps reports every match, ordered by the end position. The matches ending at
+	// the same position are ordered from the longest to the shortest.
+	AllOverlaps
+)
+
+// Match is an occurrence of a stored key in the text.
+type Match[V any] struct {
+	// The key is text[Start:End]
+	Start int
+	End   int
+	// Key refers to the text instead of the tree
+	Key   []byte
+	Value V
+}
+
+// WalkMatches scans the text for the keys stored in the tree, and calls function with each
+// match, in the order specified by mode. Once the function returns true, it will stop
+// scanning. The empty key is ignored, as it matches everywhere.
+func (tree *Tree[V]) WalkMatches(text []byte, mode FindMode, f func(match Match[V]) bool) {
+	if len(tree.root.edges) == 0 {
+		return
+	}
+	if mode == AllOverlaps {
+		tree.walkAllOverlaps(text, f)
+	} else {
+		tree.walkLeftmostLongest(text, f)
+	}
+}
+
+// FindAll returns the matches of the keys stored in the tree. See WalkMatches.
+func (tree *Tree[V]) FindAll(text []byte, mode FindMode) []Match[V] {
+	matches := []Match[V]{}
+	tree.WalkMatches(text, mode, func(match Match[V]) bool {
+		matches = append(matches, match)
+		return false
+	})
+	return matches
+}
+
+// The keys ending at each position are the suffixes of the text before this position.
+func (tree *Tree[V]) walkAllOverlaps(text []byte, f func(match Match[V]) bool) {
+	end := 0
+	report := func(leaf *_Leaf[V]) bool {
+		keyLen := len(leaf.originKey)
+		if keyLen == 0 {
+			return false
+		}
+		start := end - keyLen
+		return f(Match[V]{
+			Start: start,
+			End:   end,
+			Key:   text[start:end:end],
+			Value: leaf.value,
+		})
+	}
+	for end = 1; end <= len(text); end++ {
+		if tree.root.suffixMatches(text[:end], true, report) {
+			return
+		}
+	}
+}
+
+func (tree *Tree[V]) walkLeftmostLongest(text []byte, f func(match Match[V]) bool) {
+	pos := 0
+	for pos < len(text) {
+		var best Match[V]
+		found := false
+		for end := pos + 1; end <= len(text); end++ {
+			// A match ending here starts after end-maxKeyLen, so it can't start before
+			// the best one. Neither can the following matches.
+			if found && end-tree.maxKeyLen > best.Start {
+				break
+			}
+			// The longest key ending here is the one which starts first
+			key, value, ok := nodeLongestSuffix(tree.root, text[pos:end])
+			if !ok || len(key) == 0 {
+				continue
+			}
+			start := end - len(key)
+			// A match with the same start is longer, as it ends later
+			if !found || start <= best.Start {
+				best = Match[V]{
+					Start: start,
+					End:   end,
+					Key:   text[start:end:end],
+					Value: value,
+				}
+				found = true
+			}
+		}
+		if !found || f(best) {
+			return
+		}
+		pos = best.End
+	}
+}
@@ -0,0 +1,122 @@
+package suffix
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func formatMatches(matches []Match[string]) []string {
+	res := []string{}
+	for _, match := range matches {
+		res = append(res, string(match.Key)+"@"+string(rune('0'+match.Start)))
+	}
+	return res
+}
+
+func TestFindAll(t *testing.T) {
+	tree := New[string]()
+	assert.Equal(t, 0, len(tree.FindAll([]byte("text"), AllOverlaps)))
+	for _, word := range []string{"", "he", "she", "his", "hers", "s"} {
+		tree.InsertString(word, word)
+	}
+	text := []byte("ushers his")
+
+	matches := tree.FindAll(text, AllOverlaps)
+	assert.Equal(t, []string{"s@1", "she@1", "he@2", "hers@2", "s@5", "his@7", "s@9"},
+		formatMatches(matches))
+	for _, match := range matches {
+		assert.Equal(t, match.Value, string(text[match.Start:match.End]))
+		assert.Equal(t, match.Value, string(match.Key))
+	}
+
+	matches = tree.FindAll(text, LeftmostLongest)
+	assert.Equal(t, []string{"she@1", "s@5", "his@7"}, formatMatches(matches))
+	// "hers" is longer than "he", which starts at the same position and ends first
+	assert.Equal(t, []string{"hers@1"}, formatMatches(tree.FindAll([]byte("xhers"), LeftmostLongest)))
+	// "abcd" starts before "b", which ends first
+	other := New[string]()
+	other.InsertString("abcd", "abcd")
+	other.InsertString("b", "b")
+	assert.Equal(t, []string{"abcd@0", "b@5"},
+		formatMatches(other.FindAll([]byte("abcdab"), LeftmostLongest)))
+
+	assert.Equal(t, 0, len(tree.FindAll([]byte("xyz"), LeftmostLongest)))
+	assert.Equal(t, 0, len(tree.FindAll(nil, AllOverlaps)))
+
+	found := []string{}
+	for match := range tree.Matches(text, AllOverlaps) {
+		found = append(found, string(match.Key))
+		if len(found) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"s", "she"}, found)
+}
+
+func bruteForceFindAll(keys [][]byte, text []byte, mode FindMode) []Match[string] {
+	matches := []Match[string]{}
+	if mode == AllOverlaps {
+		for end := 1; end <= len(text); end++ {
+			for start := 0; start < end; start++ {
+				for _, key := range keys {
+					if bytes.Equal(key, text[start:end]) {
+						matches = append(matches, Match[string]{Start: start, End: end, Key: key})
+					}
+				}
+			}
+		}
+		return matches
+	}
+	for start := 0; start < len(text); {
+		end := -1
+		for _, key := range keys {
+			if len(key) > 0 && bytes.HasPrefix(text[start:], key) && start+len(key) > end {
+				end = start + len(key)
+			}
+		}
+		if end == -1 {
+			start++
+			continue
+		}
+		matches = append(matches, Match[string]{Start: start, End: end, Key: text[start:end]})
+		start = end
+	}
+	return matches
+}
+
+func TestFindAll_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("ab")
+	for turn := 0; turn < 200; turn++ {
+		tree := New[string]()
+		keys := [][]byte{}
+		for i := 0; i < 6; i++ {
+			key := randomKey(r, letters, 1, 6)
+			if _, found := tree.Get(key); !found {
+				keys = append(keys, key)
+				tree.Insert(key, string(key))
+			}
+		}
+		// The removed key leaves a stale maxKeyLen
+		if r.Intn(2) == 0 {
+			tree.Remove(keys[0])
+			keys = keys[1:]
+		}
+		text := randomKey(r, letters, 32, 32)
+		for _, mode := range []FindMode{AllOverlaps, LeftmostLongest} {
+			expected := bruteForceFindAll(keys, text, mode)
+			actual := tree.FindAll(text, mode)
+			assert.Equal(t, len(expected), len(actual))
+			for i := range expected {
+				if i < len(actual) {
+					assert.Equal(t, expected[i].Start, actual[i].Start)
+					assert.Equal(t, expected[i].End, actual[i].End)
+					assert.Equal(t, string(expected[i].Key), actual[i].Value)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package suffix
+
+import (
+	"bytes"
+)
+
+// _FlatTree is a read-only tree whose nodes and leaves are addressed by index,
+// like FrozenTree and CompactTree. The lookup algorithms are shared among them.
+// The edges of a node are ordered in the same way as _Node.
+type _FlatTree[V any] interface {
+	edgesNum(node uint32) int
+	// Return the label of the edge, the index of the point and whether the point is a leaf.
+	edge(node uint32, i int) (label []byte, point uint32, isLeaf bool)
+	leaf(leaf uint32) (key []byte, value V)
+}
+
+// _FlatWriter is the write side of _FlatTree. flatWrite walks a Tree and calls it to emit
+// the leaves and nodes, while the layout decides how to store them.
+type _FlatWriter[V any] interface {
+	// Emit the leaf, return its index and the offset of its key.
+	leaf(leaf *_Leaf[V]) (point uint32, keyOff uint32)
+	// Emit the node with its edges, return its index.
+	node(edges []_FlatEdge) uint32
+}
+
+type _FlatEdge struct {
+	labelOff uint32
+	labelLen uint32
+	point    uint32
+	isLeaf   bool
+}
+
+// Emit the tree in post order, so the indexes of children are known when their parent is
+// emitted. Return the index of root.
+// Labels are not stored separately, they refer to the keys emitted with the leaves.
+func flatWrite[V any, W _FlatWriter[V]](w W, root *_Node[V]) uint32 {
+	// The edges of the nodes on the current path
+	var edges []_FlatEdge
+	point, _ := flatWriteNode(w, root, 0, &edges)
+	return point
+}
+
+// Emit the node, return its index and the offset of the end of any label under it.
+// suffixLen is the length of the suffix consumed by the edges above the node.
+func flatWriteNode[V any, W _FlatWriter[V]](w W, node *_Node[V], suffixLen int,
+	edges *[]_FlatEdge) (point uint32, labelEnd uint32) {
+
+	first := len(*edges)
+	for _, edge := range node.edges {
+		flatEdge := _FlatEdge{labelLen: uint32(len(edge.label))}
+		switch p := edge.point.(type) {
+		case *_Leaf[V]:
+			var keyOff uint32
+			flatEdge.point, keyOff = w.leaf(p)
+			flatEdge.isLeaf = true
+			labelEnd = keyOff + uint32(len(p.originKey)-suffixLen)
+		case *_Node[V]:
+			var childLabelEnd uint32
+			flatEdge.point, childLabelEnd = flatWriteNode(w, p, suffixLen+len(edge.label), edges)
+			// The label is followed by the label of the child in the same key
+			labelEnd = childLabelEnd + uint32(len(edge.label))
+		}
+		flatEdge.labelOff = labelEnd - flatEdge.labelLen
+		*edges = append(*edges, flatEdge)
+	}
+	point = w.node((*edges)[first:])
+	*edges = (*edges)[:first]
+	return point, labelEnd
+}
+
+// Return the index of the first non-empty edge. If the node has an edge with empty label,
+// it is the first edge and points to a leaf.
+func flatFirstNonEmptyEdge[V any, T _FlatTree[V]](tree T, node uint32) int {
+	if tree.edgesNum(node) > 0 {
+		if label, _, _ := tree.edge(node, 0); len(label) == 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func flatGet[V any, T _FlatTree[V]](tree T, node uint32, key []byte) (value V, found bool) {
+	start := flatFirstNonEmptyEdge[V](tree, node)
+	if start == 1 && len(key) == 0 {
+		_, point, _ := tree.edge(node, 0)
+		_, value = tree.leaf(point)
+		return value, true
+	}
+
+	keyLen := len(key)
+	edgesNum := tree.edgesNum(node)
+	for i := start; i < edgesNum; i++ {
+		label, point, isLeaf := tree.edge(node, i)
+		if keyLen < len(label) {
+			break
+		}
+		if bytes.Equal(key[keyLen-len(label):], label) {
+			if !isLeaf {
+				return flatGet[V](tree, point, key[:keyLen-len(label)])
+			}
+			if keyLen == len(label) {
+				_, value = tree.leaf(point)
+				return value, true
+			}
+			break
+		}
+	}
+	return value, false
+}
+
+func flatLongestSuffix[V any, T _FlatTree[V]](tree T, node uint32, key []byte) (
+	matchedKey []byte, value V, found bool) {
+
+	start := flatFirstNonEmptyEdge[V](tree, node)
+	keyLen := len(key)
+	edgesNum := tree.edgesNum(node)
+	for i := start; i < edgesNum; i++ {
+		label, point, isLeaf := tree.edge(node, i)
+		if keyLen < len(label) {
+			break
+		}
+		if bytes.Equal(key[keyLen-len(label):], label) {
+			if isLeaf {
+				matchedKey, value = tree.leaf(point)
+				return matchedKey, value, true
+			}
+			matchedKey, value, found = flatLongestSuffix[V](tree, point, key[:keyLen-len(label)])
+			if found {
+				return matchedKey, value, found
+			}
+			break
+		}
+	}
+
+	if start == 1 {
+		_, point, _ := tree.edge(node, 0)
+		matchedKey, value = tree.leaf(point)
+		return matchedKey, value, true
+	}
+	return nil, value, false
+}
+
+func flatWalk[V any, T _FlatTree[V]](tree T, node uint32, f func(key []byte, value V) bool) (
+	stop bool) {
+
+	edgesNum := tree.edgesNum(node)
+	for i := 0; i < edgesNum; i++ {
+		_, point, isLeaf := tree.edge(node, i)
+		if isLeaf {
+			stop = f(tree.leaf(point))
+		} else {
+			stop = flatWalk[V](tree, point, f)
+		}
+		if stop {
+			return true
+		}
+	}
+	return false
+}
+
+func flatWalkSuffix[V any, T _FlatTree[V]](tree T, node uint32, suffix []byte,
+	f func(key []byte, value V) bool) {
+
+	for {
+		if len(suffix) == 0 {
+			flatWalk[V](tree, node, f)
+			return
+		}
+		next := false
+		// Like getPointHasSuffix, try the longest label first
+		for i := tree.edgesNum(node) - 1; i >= 0; i-- {
+			label, point, isLeaf := tree.edge(node, i)
+			if len(suffix) > len(label) {
+				if !bytes.Equal(suffix[len(suffix)-len(label):], label) {
+					continue
+				}
+				if isLeaf {
+					return
+				}
+				node = point
+				suffix = suffix[:len(suffix)-len(label)]
+				next = true
+				break
+			}
+			if bytes.HasSuffix(label, suffix) {
+				if isLeaf {
+					f(tree.leaf(point))
+				} else {
+					flatWalk[V](tree, point, f)
+				}
+				return
+			}
+		}
+		if !next {
+			return
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package suffix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// The layout of a frozen tree. All integers are uint32 in little endian.
+//
+//	body: a sequence of keys, values, leaves and nodes
+//	trailer: offset of root node | number of keys | CRC-32 of body | version | magic "SFXF"
+//
+// A leaf is: offset of key | length of key | offset of value | length of value.
+// A node is: number of edges, followed by the edges. An edge is: offset of label |
+// length of label | offset of the leaf or node it points to. The highest bit of the last
+// field is set if it points to a leaf.
+// Labels are not stored separately, they refer to the keys of the leaves under them.
+// The edges of a node are in the same order as the Tree.
+// The trailer is placed at the end, so the tree could be written in one pass.
+const (
+	frozenMagic       = "SFXF"
+	frozenVersion     = 1
+	frozenTrailerSize = 20
+	frozenLeafSize    = 16
+	frozenEdgeSize    = 12
+	frozenLeafFlag    = 1 << 31
+)
+
+type _FrozenWriter[V any] struct {
+	w     *bufio.Writer
+	off   uint64
+	crc   uint32
+	codec ValueCodec[V]
+	value []byte
+	err   error
+}
+
+func (fw *_FrozenWriter[V]) write(b []byte) uint32 {
+	off := uint32(fw.off)
+	if fw.err != nil {
+		return off
+	}
+	if fw.off+uint64(len(b)) >= frozenLeafFlag {
+		fw.err = errors.New("suffix: frozen tree is too large")
+		return off
+	}
+	_, fw.err = fw.w.Write(b)
+	fw.crc = crc32.Update(fw.crc, crc32.IEEETable, b)
+	fw.off += uint64(len(b))
+	return off
+}
+
+// Write the leaf, return its offset and the offset of its key.
+func (fw *_FrozenWriter[V]) leaf(leaf *_Leaf[V]) (off uint32, keyOff uint32) {
+	keyOff = fw.write(leaf.originKey)
+	if fw.err == nil {
+		fw.value, fw.err = fw.codec.AppendValue(fw.value[:0], leaf.value)
+	}
+	valueOff := fw.write(fw.value)
+	var record [frozenLeafSize]byte
+	binary.LittleEndian.PutUint32(record[0:], keyOff)
+	binary.LittleEndian.PutUint32(record[4:], uint32(len(leaf.originKey)))
+	binary.LittleEndian.PutUint32(record[8:], valueOff)
+	binary.LittleEndian.PutUint32(record[12:], uint32(len(fw.value)))
+	return fw.write(record[:]), keyOff
+}
+
+// Write the node, return its offset.
+func (fw *_FrozenWriter[V]) node(edges []_FlatEdge) uint32 {
+	record := make([]byte, 4+frozenEdgeSize*len(edges))
+	binary.LittleEndian.PutUint32(record, uint32(len(edges)))
+	for i, edge := range edges {
+		target := edge.point
+		if edge.isLeaf {
+			target |= frozenLeafFlag
+		}
+		entry := record[4+frozenEdgeSize*i:]
+		binary.LittleEndian.PutUint32(entry[0:], edge.labelOff)
+		binary.LittleEndian.PutUint32(entry[4:], edge.labelLen)
+		binary.LittleEndian.PutUint32(entry[8:], target)
+	}
+	return fw.write(record)
+}
+
+// WriteFrozenTree writes the tree to w in the layout read by FrozenTree.
+// The values are encoded with the codec set by Tree.SetValueCodec, GobCodec by default.
+func WriteFrozenTree[V any](w io.Writer, tree *Tree[V]) error {
+	fw := &_FrozenWriter[V]{
+		w:     bufio.NewWriter(w),
+		codec: tree.valueCodec(),
+	}
+	root := flatWrite[V](fw, tree.root)
+	if fw.err != nil {
+		return fw.err
+	}
+	var trailer [frozenTrailerSize]byte
+	binary.LittleEndian.PutUint32(trailer[0:], root)
+	binary.LittleEndian.PutUint32(trailer[4:], uint32(tree.leavesNum))
+	binary.LittleEndian.PutUint32(trailer[8:], fw.crc)
+	binary.LittleEndian.PutUint32(trailer[12:], frozenVersion)
+	copy(trailer[16:], frozenMagic)
+	if _, err := fw.w.Write(trailer[:]); err != nil {
+		return err
+	}
+	return fw.w.Flush()
+}
+
+// FrozenTree is a read-only suffix tree over the flat layout written by WriteFrozenTree.
+// It doesn't parse the data, so it could be used directly over a memory-mapped file,
+// see OpenFrozenTree. The values are the bytes encoded by the value codec of the written
+// Tree. The returned keys and values refer to the underlying data, so they should not be
+// modified, and they are invalid once the FrozenTree is closed.
+// It is safe to read a FrozenTree concurrently.
+//
+// Only the trailer is validated when a FrozenTree is created, the lookups over corrupted
+// data may panic. Call Verify to check the data if it is not trusted.
+type FrozenTree struct {
+	data    []byte
+	root    uint32
+	keysNum int
+	crc     uint32
+	close   func() error
+}
+
+// NewFrozenTree creates a FrozenTree over the data written by WriteFrozenTree.
+// The data should not be modified while the FrozenTree is in use.
+func NewFrozenTree(data []byte) (*FrozenTree, error) {
+	if len(data) < frozenTrailerSize {
+		return nil, fmt.Errorf("%w: bad magic", ErrCorrupted)
+	}
+	trailer := data[len(data)-frozenTrailerSize:]
+	if string(trailer[16:]) != frozenMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrCorrupted)
+	}
+	version := binary.LittleEndian.Uint32(trailer[12:])
+	if version != frozenVersion {
+		return nil, fmt.Errorf("suffix: unsupported version %d", version)
+	}
+	tree := &FrozenTree{
+		data:    data[:len(data)-frozenTrailerSize],
+		root:    binary.LittleEndian.Uint32(trailer[0:]),
+		keysNum: int(binary.LittleEndian.Uint32(trailer[4:])),
+		crc:     binary.LittleEndian.Uint32(trailer[8:]),
+	}
+	if uint64(tree.root)+4 > uint64(len(tree.data)) {
+		return nil, fmt.Errorf("%w: bad root offset", ErrCorrupted)
+	}
+	return tree, nil
+}
+
+// Verify checks the data with the checksum.
+func (tree *FrozenTree) Verify() error {
+	if crc32.ChecksumIEEE(tree.data) != tree.crc {
+		return fmt.Errorf("%w: checksum mismatch", ErrCorrupted)
+	}
+	return nil
+}
+
+// Close releases the underlying resource, like the memory-mapped file.
+func (tree *FrozenTree) Close() error {
+	if tree.close == nil {
+		return nil
+	}
+	err := tree.close()
+	tree.close = nil
+	tree.data = nil
+	return err
+}
+
+// Len returns the number of keys.
+func (tree *FrozenTree) Len() int {
+	return tree.keysNum
+}
+
+func (tree *FrozenTree) uint32(off uint32) uint32 {
+	return binary.LittleEndian.Uint32(tree.data[off:])
+}
+
+func (tree *FrozenTree) edgesNum(node uint32) int {
+	return int(tree.uint32(node))
+}
+
+// Return the label of the edge, the offset of the point and whether the point is a leaf.
+func (tree *FrozenTree) edge(node uint32, i int) (label []byte, point uint32, isLeaf bool) {
+	entry := node + 4 + uint32(frozenEdgeSize*i)
+	labelOff := tree.uint32(entry)
+	labelLen := tree.uint32(entry + 4)
+	point = tree.uint32(entry + 8)
+	label = tree.data[labelOff : labelOff+labelLen : labelOff+labelLen]
+	return label, point &^ frozenLeafFlag, point&frozenLeafFlag != 0
+}
+
+func (tree *FrozenTree) leaf(leaf uint32) (key []byte, value []byte) {
+	keyOff := tree.uint32(leaf)
+	keyLen := tree.uint32(leaf + 4)
+	valueOff := tree.uint32(leaf + 8)
+	valueLen := tree.uint32(leaf + 12)
+	return tree.data[keyOff : keyOff+keyLen : keyOff+keyLen],
+		tree.data[valueOff : valueOff+valueLen : valueOff+valueLen]
+}
+
+// Get returns the value of given key and a boolean to indicate
+// whether the value is found.
+func (tree *FrozenTree) Get(key []byte) (value []byte, found bool) {
+	if key == nil {
+		return nil, false
+	}
+	return flatGet[[]byte](tree, tree.root, key)
+}
+
+// LongestSuffix returns the key which is the longest suffix of the given key,
+// and the value referred by this key. See Tree.LongestSuffix.
+func (tree *FrozenTree) LongestSuffix(key []byte) (matchedKey []byte, value []byte, found bool) {
+	if key == nil {
+		return nil, nil, false
+	}
+	return flatLongestSuffix[[]byte](tree, tree.root, key)
+}
+
+// Walk through the tree, call function with key and value. See Tree.Walk.
+func (tree *FrozenTree) Walk(f func(key []byte, value []byte) bool) {
+	flatWalk[[]byte](tree, tree.root, f)
+}
+
+// WalkSuffix travels through nodes which have given suffix, calls function with key and
+// value. See Tree.WalkSuffix.
+func (tree *FrozenTree) WalkSuffix(suffix []byte, f func(key []byte, value []byte) bool) {
+	flatWalkSuffix[[]byte](tree, tree.root, suffix, f)
+}
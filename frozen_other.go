@@ -0,0 +1,17 @@
+//go:build !unix
+
+package suffix
+
+import (
+	"os"
+)
+
+// OpenFrozenTree reads the file written by WriteFrozenTree, and creates a FrozenTree over it.
+// On this platform the file is read into memory instead of being memory-mapped.
+func OpenFrozenTree(path string) (*FrozenTree, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFrozenTree(data)
+}
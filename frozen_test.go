@@ -0,0 +1,188 @@
+package suffix
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bytesCodec struct{}
+
+func (bytesCodec) AppendValue(dst []byte, value string) ([]byte, error) {
+	return append(dst, value...), nil
+}
+
+func (bytesCodec) DecodeValue(data []byte) (string, error) {
+	return string(data), nil
+}
+
+func freeze(t *testing.T, tree *Tree[string]) *FrozenTree {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, WriteFrozenTree(buf, tree))
+	frozen, err := NewFrozenTree(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, frozen.Verify())
+	return frozen
+}
+
+func assertSameAsFrozen(t *testing.T, tree *Tree[string], frozen *FrozenTree, queries []string) {
+	assert.Equal(t, tree.Len(), frozen.Len())
+
+	walked := []string{}
+	tree.Walk(func(key []byte, value string) bool {
+		walked = append(walked, string(key))
+		return false
+	})
+	frozenWalked := []string{}
+	frozen.Walk(func(key []byte, value []byte) bool {
+		assert.Equal(t, string(key), string(value))
+		frozenWalked = append(frozenWalked, string(key))
+		return false
+	})
+	assert.Equal(t, walked, frozenWalked)
+
+	for _, query := range queries {
+		value, found := tree.Get([]byte(query))
+		frozenValue, frozenFound := frozen.Get([]byte(query))
+		assert.Equal(t, found, frozenFound, "Get %s", query)
+		assert.Equal(t, value, string(frozenValue), "Get %s", query)
+
+		matchedKey, value, found := tree.LongestSuffix([]byte(query))
+		frozenMatchedKey, frozenValue, frozenFound := frozen.LongestSuffix([]byte(query))
+		assert.Equal(t, found, frozenFound, "LongestSuffix %s", query)
+		assert.Equal(t, string(matchedKey), string(frozenMatchedKey), "LongestSuffix %s", query)
+		assert.Equal(t, value, string(frozenValue), "LongestSuffix %s", query)
+
+		walked = []string{}
+		tree.WalkSuffix([]byte(query), func(key []byte, value string) bool {
+			walked = append(walked, string(key))
+			return false
+		})
+		frozenWalked = []string{}
+		frozen.WalkSuffix([]byte(query), func(key []byte, value []byte) bool {
+			frozenWalked = append(frozenWalked, string(key))
+			return false
+		})
+		assert.Equal(t, walked, frozenWalked, "WalkSuffix %s", query)
+	}
+}
+
+func TestFrozenTree_Base(t *testing.T) {
+	tree := New[string]()
+	tree.SetValueCodec(bytesCodec{})
+	frozen := freeze(t, tree)
+	assertSameAsFrozen(t, tree, frozen, []string{"", "able"})
+
+	lists := []string{
+		"edible", "presentable", "abominable", "credible",
+		"picturesque", "statuesque", "nothing", "something", "thing", "nonsense",
+		"random word", "word", "table", "unbelievable", "believable", "sense", "",
+	}
+	for _, s := range lists {
+		tree.Insert([]byte(s), s)
+	}
+	frozen = freeze(t, tree)
+	queries := append(lists, "unpresentable", "able", "ble", "x", "incredible", "esque", "ing")
+	assertSameAsFrozen(t, tree, frozen, queries)
+
+	_, found := frozen.Get(nil)
+	assert.False(t, found)
+	_, _, found = frozen.LongestSuffix(nil)
+	assert.False(t, found)
+
+	count := 0
+	frozen.WalkSuffix([]byte("able"), func(key []byte, value []byte) bool {
+		count++
+		return count == 2
+	})
+	assert.Equal(t, 2, count)
+}
+
+func TestFrozenTree_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	tree := New[string]()
+	tree.SetValueCodec(bytesCodec{})
+	queries := []string{}
+	for i := 0; i < 1024; i++ {
+		b := randomKey(r, letters, 0, 9)
+		queries = append(queries, string(b))
+		if i%2 == 0 {
+			tree.Insert(b, string(b))
+		}
+	}
+	assertSameAsFrozen(t, tree, freeze(t, tree), queries)
+}
+
+func TestFrozenTree_File(t *testing.T) {
+	tree := New[string]()
+	tree.SetValueCodec(bytesCodec{})
+	for _, s := range []string{"able", "table", "presentable", "word"} {
+		tree.Insert([]byte(s), s)
+	}
+	path := filepath.Join(t.TempDir(), "tree.frozen")
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	assert.Nil(t, WriteFrozenTree(file, tree))
+	assert.Nil(t, file.Close())
+
+	frozen, err := OpenFrozenTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, frozen.Verify())
+	matchedKey, value, found := frozen.LongestSuffix([]byte("unpresentable"))
+	assert.True(t, found)
+	assert.Equal(t, "presentable", string(matchedKey))
+	assert.Equal(t, "presentable", string(value))
+	assert.Nil(t, frozen.Close())
+	assert.Nil(t, frozen.Close())
+
+	_, err = OpenFrozenTree(filepath.Join(t.TempDir(), "nonexist"))
+	assert.NotNil(t, err)
+}
+
+func TestFrozenTree_Corrupted(t *testing.T) {
+	tree := New[string]()
+	tree.SetValueCodec(bytesCodec{})
+	tree.Insert([]byte("able"), "able")
+	buf := &bytes.Buffer{}
+	assert.Nil(t, WriteFrozenTree(buf, tree))
+	data := buf.Bytes()
+
+	_, err := NewFrozenTree(data[:len(data)-1])
+	assert.True(t, errors.Is(err, ErrCorrupted))
+	_, err = NewFrozenTree(nil)
+	assert.True(t, errors.Is(err, ErrCorrupted))
+
+	corrupted := append([]byte{}, data...)
+	corrupted[0] ^= 1
+	frozen, err := NewFrozenTree(corrupted)
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(frozen.Verify(), ErrCorrupted))
+
+	corrupted = append([]byte{}, data...)
+	corrupted[len(data)-frozenTrailerSize+12] = frozenVersion + 1
+	_, err = NewFrozenTree(corrupted)
+	assert.EqualError(t, err, "suffix: unsupported version 2")
+
+	tree.SetValueCodec(failedStringCodec{})
+	assert.EqualError(t, WriteFrozenTree(&bytes.Buffer{}, tree), "failed to encode")
+}
+
+type failedStringCodec struct{}
+
+func (failedStringCodec) AppendValue(dst []byte, value string) ([]byte, error) {
+	return dst, errors.New("failed to encode")
+}
+
+func (failedStringCodec) DecodeValue(data []byte) (string, error) {
+	return "", errors.New("failed to decode")
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package suffix
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenFrozenTree maps the file written by WriteFrozenTree into memory, and creates a
+// FrozenTree over it. The mapping is read-only and shared, so the processes which open the
+// same file share the page cache. Call Close to unmap the file.
+func OpenFrozenTree(path string) (*FrozenTree, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := int(info.Size())
+	if size == 0 {
+		return NewFrozenTree(nil)
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := NewFrozenTree(data)
+	if err != nil {
+		// The format error is more useful than a failure to unmap
+		_ = syscall.Munmap(data)
+		return nil, err
+	}
+	tree.close = func() error {
+		return syscall.Munmap(data)
+	}
+	return tree, nil
+}
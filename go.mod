@@ -0,0 +1,12 @@
+module github.com/spacewander/go-suffix-tree
+
+// The iterators in iter.go need range-over-func from Go 1.23
+go 1.23
+
+require github.com/stretchr/testify v1.9.0
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
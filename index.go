@@ -0,0 +1,233 @@
+package suffix
+
+import (
+	"bytes"
+)
+
+// The labels of a node don't share common suffix, except the empty label. So each non-empty
+// label could be found by its last byte, which is also the next byte read from the tail of
+// the key. _Index maps the last byte of label to the edge, like the inner nodes of an
+// Adaptive Radix Tree, it grows and shrinks among four sizes as edges are added and removed:
+//
+//   - _Index4 and _Index16 store the bytes in an array, and scan it.
+//   - _Index48 maps the byte to a slot of 48 edges.
+//   - _Index256 maps the byte to the edge directly.
+//
+// The edge with empty label is not indexed.
+type _Index[V any] interface {
+	find(b byte) *_Edge[V]
+	// Return the index which contains the new edge. It may be a larger index.
+	add(b byte, edge *_Edge[V]) _Index[V]
+	// Return the index without the edge. It may be a smaller index.
+	remove(b byte) _Index[V]
+	len() int
+}
+
+const (
+	// Shrink the index when it is much smaller than its capacity, to avoid
+	// growing and shrinking repeatedly around the boundary.
+	index16MinLen  = 4
+	index48MinLen  = 12
+	index256MinLen = 37
+)
+
+func lastByte(label []byte) byte {
+	return label[len(label)-1]
+}
+
+type _Index4[V any] struct {
+	keys  [4]byte
+	edges [4]*_Edge[V]
+	n     uint8
+}
+
+func (idx *_Index4[V]) find(b byte) *_Edge[V] {
+	for i := 0; i < int(idx.n); i++ {
+		if idx.keys[i] == b {
+			return idx.edges[i]
+		}
+	}
+	return nil
+}
+
+func (idx *_Index4[V]) add(b byte, edge *_Edge[V]) _Index[V] {
+	if idx.n == 4 {
+		larger := &_Index16[V]{n: 4}
+		copy(larger.keys[:], idx.keys[:])
+		copy(larger.edges[:], idx.edges[:])
+		return larger.add(b, edge)
+	}
+	idx.keys[idx.n] = b
+	idx.edges[idx.n] = edge
+	idx.n++
+	return idx
+}
+
+func (idx *_Index4[V]) remove(b byte) _Index[V] {
+	for i := 0; i < int(idx.n); i++ {
+		if idx.keys[i] == b {
+			// Move the last one to fill the hole
+			last := idx.n - 1
+			idx.keys[i] = idx.keys[last]
+			idx.edges[i] = idx.edges[last]
+			idx.edges[last] = nil
+			idx.n--
+			break
+		}
+	}
+	return idx
+}
+
+func (idx *_Index4[V]) len() int {
+	return int(idx.n)
+}
+
+type _Index16[V any] struct {
+	keys  [16]byte
+	edges [16]*_Edge[V]
+	n     uint8
+}
+
+func (idx *_Index16[V]) find(b byte) *_Edge[V] {
+	// bytes.IndexByte is vectorized on most platforms
+	i := bytes.IndexByte(idx.keys[:idx.n], b)
+	if i == -1 {
+		return nil
+	}
+	return idx.edges[i]
+}
+
+func (idx *_Index16[V]) add(b byte, edge *_Edge[V]) _Index[V] {
+	if idx.n == 16 {
+		larger := &_Index48[V]{}
+		for i := 0; i < 16; i++ {
+			larger.add(idx.keys[i], idx.edges[i])
+		}
+		return larger.add(b, edge)
+	}
+	idx.keys[idx.n] = b
+	idx.edges[idx.n] = edge
+	idx.n++
+	return idx
+}
+
+func (idx *_Index16[V]) remove(b byte) _Index[V] {
+	i := bytes.IndexByte(idx.keys[:idx.n], b)
+	if i == -1 {
+		return idx
+	}
+	last := idx.n - 1
+	idx.keys[i] = idx.keys[last]
+	idx.edges[i] = idx.edges[last]
+	idx.edges[last] = nil
+	idx.n--
+	if idx.n < index16MinLen {
+		smaller := &_Index4[V]{n: idx.n}
+		copy(smaller.keys[:], idx.keys[:idx.n])
+		copy(smaller.edges[:], idx.edges[:idx.n])
+		return smaller
+	}
+	return idx
+}
+
+func (idx *_Index16[V]) len() int {
+	return int(idx.n)
+}
+
+type _Index48[V any] struct {
+	// 0 means not found, otherwise it is the position in edges plus one
+	slots [256]uint8
+	edges [48]*_Edge[V]
+	n     uint8
+}
+
+func (idx *_Index48[V]) find(b byte) *_Edge[V] {
+	slot := idx.slots[b]
+	if slot == 0 {
+		return nil
+	}
+	return idx.edges[slot-1]
+}
+
+func (idx *_Index48[V]) add(b byte, edge *_Edge[V]) _Index[V] {
+	if idx.n == 48 {
+		larger := &_Index256[V]{n: 48}
+		for _, e := range idx.edges {
+			larger.edges[lastByte(e.label)] = e
+		}
+		return larger.add(b, edge)
+	}
+	idx.edges[idx.n] = edge
+	idx.n++
+	idx.slots[b] = idx.n
+	return idx
+}
+
+func (idx *_Index48[V]) remove(b byte) _Index[V] {
+	slot := idx.slots[b]
+	if slot == 0 {
+		return idx
+	}
+	i := slot - 1
+	last := idx.n - 1
+	if i != last {
+		// Move the last one to fill the hole
+		idx.edges[i] = idx.edges[last]
+		idx.slots[lastByte(idx.edges[i].label)] = slot
+	}
+	idx.edges[last] = nil
+	idx.slots[b] = 0
+	idx.n--
+	if idx.n < index48MinLen {
+		smaller := &_Index16[V]{n: idx.n}
+		for i, e := range idx.edges[:idx.n] {
+			smaller.keys[i] = lastByte(e.label)
+			smaller.edges[i] = e
+		}
+		return smaller
+	}
+	return idx
+}
+
+func (idx *_Index48[V]) len() int {
+	return int(idx.n)
+}
+
+type _Index256[V any] struct {
+	edges [256]*_Edge[V]
+	n     uint16
+}
+
+func (idx *_Index256[V]) find(b byte) *_Edge[V] {
+	return idx.edges[b]
+}
+
+func (idx *_Index256[V]) add(b byte, edge *_Edge[V]) _Index[V] {
+	if idx.edges[b] == nil {
+		idx.n++
+	}
+	idx.edges[b] = edge
+	return idx
+}
+
+func (idx *_Index256[V]) remove(b byte) _Index[V] {
+	if idx.edges[b] == nil {
+		return idx
+	}
+	idx.edges[b] = nil
+	idx.n--
+	if idx.n < index256MinLen {
+		smaller := &_Index48[V]{}
+		for _, e := range idx.edges {
+			if e != nil {
+				smaller.add(lastByte(e.label), e)
+			}
+		}
+		return smaller
+	}
+	return idx
+}
+
+func (idx *_Index256[V]) len() int {
+	return int(idx.n)
+}
@@ -0,0 +1,123 @@
+package suffix
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func indexKind[V any](index _Index[V]) int {
+	switch index.(type) {
+	case *_Index4[V]:
+		return 4
+	case *_Index16[V]:
+		return 16
+	case *_Index48[V]:
+		return 48
+	case *_Index256[V]:
+		return 256
+	}
+	return 0
+}
+
+func assertIndexMatchesEdges[V any](t *testing.T, node *_Node[V]) {
+	indexed := 0
+	for _, edge := range node.edges {
+		if len(edge.label) == 0 {
+			continue
+		}
+		indexed++
+		assert.True(t, node.index.find(lastByte(edge.label)) == edge)
+		if child, ok := edge.point.(*_Node[V]); ok {
+			assertIndexMatchesEdges(t, child)
+		}
+	}
+	assert.Equal(t, indexed, node.index.len())
+}
+
+func TestIndexGrowAndShrink(t *testing.T) {
+	tree := New[int]()
+	tree.Insert([]byte{}, -1)
+	expectedKinds := map[int]int{1: 4, 4: 4, 5: 16, 16: 16, 17: 48, 48: 48, 49: 256, 256: 256}
+	for i := 0; i < 256; i++ {
+		tree.Insert([]byte{'a', byte(i)}, i)
+		if kind, ok := expectedKinds[i+1]; ok {
+			assert.Equal(t, kind, indexKind(tree.root.index), fmt.Sprintf("%d edges", i+1))
+		}
+	}
+	assertIndexMatchesEdges(t, tree.root)
+	for i := 0; i < 256; i++ {
+		value, found := tree.Get([]byte{'a', byte(i)})
+		assert.True(t, found)
+		assert.Equal(t, i, value)
+	}
+
+	expectedKinds = map[int]int{37: 256, 36: 48, 12: 48, 11: 16, 4: 16, 3: 4, 0: 4}
+	for i := 255; i >= 0; i-- {
+		_, found := tree.Remove([]byte{'a', byte(i)})
+		assert.True(t, found)
+		if kind, ok := expectedKinds[i]; ok {
+			assert.Equal(t, kind, indexKind(tree.root.index), fmt.Sprintf("%d edges", i))
+		}
+		assertIndexMatchesEdges(t, tree.root)
+	}
+	value, found := tree.Get([]byte{})
+	assert.True(t, found)
+	assert.Equal(t, -1, value)
+}
+
+func TestIndexRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := make([]byte, 64)
+	for i := range letters {
+		letters[i] = byte(i)
+	}
+	tree := New[string]()
+	keys := map[string]bool{}
+	for i := 0; i < 20000; i++ {
+		key := randomKey(r, letters, 0, 3)
+		if r.Intn(3) == 0 {
+			_, found := tree.Remove(key)
+			assert.Equal(t, keys[string(key)], found)
+			delete(keys, string(key))
+		} else {
+			tree.Insert(key, string(key))
+			keys[string(key)] = true
+		}
+	}
+	assertIndexMatchesEdges(t, tree.root)
+	assert.Equal(t, len(keys), tree.Len())
+	for key := range keys {
+		value, found := tree.Get([]byte(key))
+		assert.True(t, found)
+		assert.Equal(t, key, value)
+	}
+}
+
+func TestIndexAfterUnmarshal(t *testing.T) {
+	tree := New[string]()
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("%d.com", i*7919)
+		tree.Insert([]byte(key), key)
+	}
+	data, err := tree.MarshalBinary()
+	assert.Nil(t, err)
+	decoded := New[string]()
+	assert.Nil(t, decoded.UnmarshalBinary(data))
+	assertIndexMatchesEdges(t, decoded.root)
+}
+
+func BenchmarkGetWideNode(b *testing.B) {
+	tree := New[int]()
+	keys := make([][]byte, 256)
+	for i := range keys {
+		keys[i] = []byte{'k', byte(i)}
+		tree.Insert(keys[i], i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Get(keys[i&255])
+	}
+}
@@ -0,0 +1,63 @@
+package suffix
+
+import (
+	"iter"
+)
+
+// All returns an iterator over all keys and values in the tree.
+// The travelling order is the same as Walk: DFS, in the same suffix level the shortest key comes first.
+func (tree *Tree[V]) All() iter.Seq2[[]byte, V] {
+	return func(yield func(key []byte, value V) bool) {
+		tree.Walk(func(key []byte, value V) bool {
+			return !yield(key, value)
+		})
+	}
+}
+
+// Keys returns an iterator over all keys in the tree, in the same order as All.
+func (tree *Tree[V]) Keys() iter.Seq[[]byte] {
+	return func(yield func(key []byte) bool) {
+		tree.Walk(func(key []byte, _ V) bool {
+			return !yield(key)
+		})
+	}
+}
+
+// Values returns an iterator over all values in the tree, in the same order as All.
+func (tree *Tree[V]) Values() iter.Seq[V] {
+	return func(yield func(value V) bool) {
+		tree.Walk(func(_ []byte, value V) bool {
+			return !yield(value)
+		})
+	}
+}
+
+// WithSuffix returns an iterator over keys which have given suffix and their values.
+// The travelling order is the same as WalkSuffix.
+func (tree *Tree[V]) WithSuffix(suffix []byte) iter.Seq2[[]byte, V] {
+	return func(yield func(key []byte, value V) bool) {
+		tree.WalkSuffix(suffix, func(key []byte, value V) bool {
+			return !yield(key, value)
+		})
+	}
+}
+
+// AllSuffixMatches returns an iterator over every key which is a suffix of the given key,
+// and the value referred by this key. The order is the same as WalkSuffixMatches.
+func (tree *Tree[V]) AllSuffixMatches(key []byte, order MatchOrder) iter.Seq2[[]byte, V] {
+	return func(yield func(matchedKey []byte, value V) bool) {
+		tree.WalkSuffixMatches(key, order, func(matchedKey []byte, value V) bool {
+			return !yield(matchedKey, value)
+		})
+	}
+}
+
+// Matches returns an iterator over the matches of the keys stored in the tree.
+// See WalkMatches.
+func (tree *Tree[V]) Matches(text []byte, mode FindMode) iter.Seq[Match[V]] {
+	return func(yield func(match Match[V]) bool) {
+		tree.WalkMatches(text, mode, func(match Match[V]) bool {
+			return !yield(match)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package suffix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// ValueCodec encodes and decodes the values when a Tree is marshaled.
+type ValueCodec[V any] interface {
+	// AppendValue appends the encoded value to dst and returns the extended buffer.
+	AppendValue(dst []byte, value V) ([]byte, error)
+	// DecodeValue decodes the value from data. The data should not be retained.
+	DecodeValue(data []byte) (V, error)
+}
+
+// GobCodec encodes each value with encoding/gob. It is the default ValueCodec of Tree.
+// As each value is encoded separately, the type information is repeated for each value.
+// Use a specific ValueCodec if the size of the output matters.
+type GobCodec[V any] struct{}
+
+// The value is wrapped, so that interface values could be encoded as well.
+type _GobValue[V any] struct {
+	Value V
+}
+
+// AppendValue implements ValueCodec.
+func (GobCodec[V]) AppendValue(dst []byte, value V) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	if err := gob.NewEncoder(buf).Encode(&_GobValue[V]{Value: value}); err != nil {
+		return dst, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeValue implements ValueCodec.
+func (GobCodec[V]) DecodeValue(data []byte) (V, error) {
+	var wrapped _GobValue[V]
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&wrapped)
+	return wrapped.Value, err
+}
+
+// ErrCorrupted is returned when unmarshaling malformed data.
+var ErrCorrupted = errors.New("suffix: corrupted data")
+
+const (
+	binaryMagic   = "SFXT"
+	binaryVersion = 1
+
+	binaryLeaf byte = 0
+	binaryNode byte = 1
+)
+
+// SetValueCodec sets the codec used to marshal and unmarshal values.
+func (tree *Tree[V]) SetValueCodec(codec ValueCodec[V]) {
+	tree.codec = codec
+}
+
+func (tree *Tree[V]) valueCodec() ValueCodec[V] {
+	if tree.codec == nil {
+		return GobCodec[V]{}
+	}
+	return tree.codec
+}
+
+type _Encoder[V any] struct {
+	data []byte
+	// Reused buffer for encoding values
+	value []byte
+	codec ValueCodec[V]
+}
+
+func (e *_Encoder[V]) node(node *_Node[V]) error {
+	var err error
+	e.data = binary.AppendUvarint(e.data, uint64(len(node.edges)))
+	for _, edge := range node.edges {
+		e.data = binary.AppendUvarint(e.data, uint64(len(edge.label)))
+		e.data = append(e.data, edge.label...)
+		switch point := edge.point.(type) {
+		case *_Leaf[V]:
+			e.data = append(e.data, binaryLeaf)
+			e.value, err = e.codec.AppendValue(e.value[:0], point.value)
+			if err != nil {
+				return err
+			}
+			e.data = binary.AppendUvarint(e.data, uint64(len(e.value)))
+			e.data = append(e.data, e.value...)
+		case *_Node[V]:
+			e.data = append(e.data, binaryNode)
+			if err = e.node(point); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The output keeps the structure of
+// the tree, so UnmarshalBinary doesn't need to insert the keys one by one.
+// The values are encoded with the codec set by SetValueCodec, GobCodec by default.
+//
+// The format is:
+//
+//	magic "SFXT" | version (1 byte) | number of keys (uvarint) | root node | CRC-32 (4 bytes)
+//
+// A node is the number of edges (uvarint) followed by the edges. An edge is the length
+// of label (uvarint), the label, then 0 and the length-prefixed value for a leaf,
+// or 1 and the child node.
+func (tree *Tree[V]) MarshalBinary() ([]byte, error) {
+	e := &_Encoder[V]{
+		data:  append([]byte(binaryMagic), binaryVersion),
+		codec: tree.valueCodec(),
+	}
+	e.data = binary.AppendUvarint(e.data, uint64(tree.leavesNum))
+	if err := e.node(tree.root); err != nil {
+		return nil, err
+	}
+	return binary.LittleEndian.AppendUint32(e.data, crc32.ChecksumIEEE(e.data)), nil
+}
+
+type _Decoder[V any] struct {
+	data      []byte
+	pos       int
+	codec     ValueCodec[V]
+	leavesNum int
+	maxKeyLen int
+}
+
+func (d *_Decoder[V]) uvarint() (int, error) {
+	n, size := binary.Uvarint(d.data[d.pos:])
+	if size <= 0 || n > uint64(len(d.data)) {
+		return 0, fmt.Errorf("%w: bad length at %d", ErrCorrupted, d.pos)
+	}
+	d.pos += size
+	return int(n), nil
+}
+
+func (d *_Decoder[V]) bytes(n int) ([]byte, error) {
+	if n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrCorrupted)
+	}
+	b := d.data[d.pos : d.pos+n : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *_Decoder[V]) node(suffix []byte, root bool) (*_Node[V], error) {
+	edgesNum, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if !root && edgesNum < 2 {
+		return nil, fmt.Errorf("%w: node has less than two edges", ErrCorrupted)
+	}
+	node := newNode(make([]*_Edge[V], 0, edgesNum)...)
+	prevLabelLen := 0
+	for i := 0; i < edgesNum; i++ {
+		labelLen, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if labelLen < prevLabelLen || (i > 0 && labelLen == 0) {
+			return nil, fmt.Errorf("%w: edges out of order", ErrCorrupted)
+		}
+		prevLabelLen = labelLen
+		label, err := d.bytes(labelLen)
+		if err != nil {
+			return nil, err
+		}
+		kind, err := d.bytes(1)
+		if err != nil {
+			return nil, err
+		}
+		edge := &_Edge[V]{
+			label: label,
+		}
+		switch kind[0] {
+		case binaryLeaf:
+			valueLen, err := d.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := d.bytes(valueLen)
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.codec.DecodeValue(encoded)
+			if err != nil {
+				return nil, err
+			}
+			originKey := make([]byte, 0, len(label)+len(suffix))
+			originKey = append(append(originKey, label...), suffix...)
+			edge.point = &_Leaf[V]{
+				originKey: originKey,
+				value:     value,
+			}
+			d.leavesNum++
+			if len(originKey) > d.maxKeyLen {
+				d.maxKeyLen = len(originKey)
+			}
+		case binaryNode:
+			childSuffix := make([]byte, 0, len(label)+len(suffix))
+			childSuffix = append(append(childSuffix, label...), suffix...)
+			child, err := d.node(childSuffix, false)
+			if err != nil {
+				return nil, err
+			}
+			edge.point = child
+		default:
+			return nil, fmt.Errorf("%w: unknown edge kind %d", ErrCorrupted, kind[0])
+		}
+		if labelLen > 0 {
+			if node.index.find(lastByte(label)) != nil {
+				return nil, fmt.Errorf("%w: labels share common suffix", ErrCorrupted)
+			}
+			node.index = node.index.add(lastByte(label), edge)
+		}
+		node.edges = append(node.edges, edge)
+		node.leavesNum += edge.leavesNum()
+	}
+	return node, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces the content of tree
+// with the data produced by MarshalBinary. The values are decoded with the codec set by
+// SetValueCodec, GobCodec by default.
+func (tree *Tree[V]) UnmarshalBinary(data []byte) error {
+	headerLen := len(binaryMagic) + 1
+	if len(data) < headerLen+crc32.Size || string(data[:len(binaryMagic)]) != binaryMagic {
+		return fmt.Errorf("%w: bad magic", ErrCorrupted)
+	}
+	if data[len(binaryMagic)] != binaryVersion {
+		return fmt.Errorf("suffix: unsupported version %d", data[len(binaryMagic)])
+	}
+	body := data[:len(data)-crc32.Size]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[len(body):]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrCorrupted)
+	}
+
+	d := &_Decoder[V]{
+		// The labels refer to the data, so copy it
+		data:  append([]byte{}, body...),
+		pos:   headerLen,
+		codec: tree.valueCodec(),
+	}
+	leavesNum, err := d.uvarint()
+	if err != nil {
+		return err
+	}
+	root, err := d.node([]byte{}, true)
+	if err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("%w: trailing data", ErrCorrupted)
+	}
+	if d.leavesNum != leavesNum {
+		return fmt.Errorf("%w: expected %d keys, actual %d", ErrCorrupted, leavesNum, d.leavesNum)
+	}
+	tree.root = root
+	tree.leavesNum = leavesNum
+	tree.maxKeyLen = d.maxKeyLen
+	return nil
+}
@@ -0,0 +1,146 @@
+package suffix
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Tree[int])(nil)
+	_ encoding.BinaryUnmarshaler = (*Tree[int])(nil)
+)
+
+type intCodec struct{}
+
+func (intCodec) AppendValue(dst []byte, value int) ([]byte, error) {
+	return strconv.AppendInt(dst, int64(value), 10), nil
+}
+
+func (intCodec) DecodeValue(data []byte) (int, error) {
+	return strconv.Atoi(string(data))
+}
+
+type failedCodec struct{}
+
+func (failedCodec) AppendValue(dst []byte, value int) ([]byte, error) {
+	return dst, errors.New("failed to encode")
+}
+
+func (failedCodec) DecodeValue(data []byte) (int, error) {
+	return 0, errors.New("failed to decode")
+}
+
+func assertSameStructure[V any](t *testing.T, expected *Tree[V], actual *Tree[V]) {
+	expectedNodes := []string{}
+	expected.walkNode(func(labels [][]byte, value V) {
+		expectedNodes = append(expectedNodes, fmt.Sprintf("%q %v", labels, value))
+	})
+	actualNodes := []string{}
+	actual.walkNode(func(labels [][]byte, value V) {
+		actualNodes = append(actualNodes, fmt.Sprintf("%q %v", labels, value))
+	})
+	assert.Equal(t, expectedNodes, actualNodes)
+	assert.Equal(t, expected.Len(), actual.Len())
+	assertNodeLeavesNum(t, actual.root)
+}
+
+func TestMarshalBinary_Gob(t *testing.T) {
+	lists, tree := getFixtures()
+	tree.Insert([]byte(""), "")
+	data, err := tree.MarshalBinary()
+	assert.Nil(t, err)
+
+	newTree := NewTree()
+	assert.Nil(t, newTree.UnmarshalBinary(data))
+	assertSameStructure(t, tree, newTree)
+	for _, s := range lists {
+		value, found := newTree.Get([]byte(s))
+		assert.True(t, found)
+		assert.Equal(t, s, value.(string))
+		matchedKey, _, _ := newTree.LongestSuffix([]byte("x" + s))
+		assert.Equal(t, s, string(matchedKey))
+	}
+
+	// The unmarshaled tree could be modified as usual
+	newTree.Remove([]byte("table"))
+	newTree.Insert([]byte("portable"), "portable")
+	_, found := newTree.Get([]byte("portable"))
+	assert.True(t, found)
+	assert.Equal(t, len(lists)+1, newTree.Len())
+}
+
+func TestMarshalBinary_Codec(t *testing.T) {
+	tree := New[int]()
+	tree.SetValueCodec(intCodec{})
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abcd")
+	for i := 0; i < 512; i++ {
+		b := randomKey(r, letters, 0, 9)
+		tree.Insert(b, i)
+		if i%3 == 0 {
+			tree.Remove(b)
+		}
+	}
+	data, err := tree.MarshalBinary()
+	assert.Nil(t, err)
+
+	newTree := New[int]()
+	newTree.SetValueCodec(intCodec{})
+	assert.Nil(t, newTree.UnmarshalBinary(data))
+	assertSameStructure(t, tree, newTree)
+	tree.Walk(func(key []byte, value int) bool {
+		actual, found := newTree.Get(key)
+		assert.True(t, found)
+		assert.Equal(t, value, actual)
+		return false
+	})
+
+	empty := New[int]()
+	data, err = empty.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Nil(t, newTree.UnmarshalBinary(data))
+	assert.Equal(t, 0, newTree.Len())
+	_, found := newTree.Get([]byte{})
+	assert.False(t, found)
+}
+
+func TestUnmarshalBinary_Corrupted(t *testing.T) {
+	tree := New[int]()
+	tree.SetValueCodec(intCodec{})
+	for i, s := range []string{"able", "table", "presentable", "word"} {
+		tree.Insert([]byte(s), i)
+	}
+	data, _ := tree.MarshalBinary()
+
+	newTree := New[int]()
+	newTree.SetValueCodec(intCodec{})
+	err := newTree.UnmarshalBinary(data[:len(data)-1])
+	assert.True(t, errors.Is(err, ErrCorrupted))
+	err = newTree.UnmarshalBinary([]byte("SFX"))
+	assert.True(t, errors.Is(err, ErrCorrupted))
+
+	for i := len(binaryMagic) + 1; i < len(data); i++ {
+		corrupted := append([]byte{}, data...)
+		corrupted[i] ^= 0x10
+		err = newTree.UnmarshalBinary(corrupted)
+		assert.True(t, errors.Is(err, ErrCorrupted), "flip byte %d", i)
+	}
+
+	corrupted := append([]byte{}, data...)
+	corrupted[len(binaryMagic)] = binaryVersion + 1
+	assert.EqualError(t, newTree.UnmarshalBinary(corrupted), "suffix: unsupported version 2")
+	// The tree is unchanged after failures
+	assert.Equal(t, 0, newTree.Len())
+
+	newTree.SetValueCodec(failedCodec{})
+	assert.EqualError(t, newTree.UnmarshalBinary(data), "failed to decode")
+	tree.SetValueCodec(failedCodec{})
+	_, err = tree.MarshalBinary()
+	assert.EqualError(t, err, "failed to encode")
+}
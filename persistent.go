@@ -0,0 +1,188 @@
+package suffix
+
+import (
+	"bytes"
+	"iter"
+)
+
+// Copy the node, its edges and the leaves under them. Child nodes are shared.
+func (node *_Node[V]) clone() *_Node[V] {
+	leavesNum := 0
+	for _, edge := range node.edges {
+		if _, ok := edge.point.(*_Leaf[V]); ok {
+			leavesNum++
+		}
+	}
+	// Allocate the edges and the leaves in batch
+	edges := make([]_Edge[V], len(node.edges))
+	leaves := make([]_Leaf[V], 0, leavesNum)
+	edgePtrs := make([]*_Edge[V], len(node.edges), len(node.edges)+1)
+	for i, edge := range node.edges {
+		edges[i] = *edge
+		if leaf, ok := edge.point.(*_Leaf[V]); ok {
+			leaves = append(leaves, *leaf)
+			edges[i].point = &leaves[len(leaves)-1]
+		}
+		edgePtrs[i] = &edges[i]
+	}
+	return newNode(edgePtrs...)
+}
+
+// Copy the nodes along the path of given key, and return the copied node.
+// The in-place insert/remove of the same key only modifies the nodes along the path,
+// so they can be applied to the copied node without touching the original one.
+// Nodes in owned are not copied again, and the copied nodes are added to owned.
+// A nil owned means nothing is owned.
+func (node *_Node[V]) clonePath(key []byte, owned map[*_Node[V]]struct{}) *_Node[V] {
+	copied := node
+	if _, ok := owned[node]; !ok {
+		copied = node.clone()
+		if owned != nil {
+			owned[copied] = struct{}{}
+		}
+	}
+	if edge := matchEdge(copied, key); edge != nil {
+		if child, ok := edge.point.(*_Node[V]); ok {
+			edge.point = child.clonePath(key[:len(key)-len(edge.label)], owned)
+		}
+	}
+	return copied
+}
+
+// ImmutableTree is a persistent suffix tree. Insert and Remove return a new tree, and
+// leave the original one unchanged. The new tree shares the unchanged nodes with the
+// original one, only the nodes along the path of the key are copied.
+// As an ImmutableTree is never modified, it is safe to read it concurrently without lock.
+type ImmutableTree[V any] struct {
+	tree Tree[V]
+}
+
+// NewImmutableTree creates an empty ImmutableTree.
+func NewImmutableTree[V any]() *ImmutableTree[V] {
+	return &ImmutableTree[V]{
+		tree: Tree[V]{
+			root: newNode[V](),
+		},
+	}
+}
+
+// Return a new tree which copies the nodes along the path of given key.
+func (t *ImmutableTree[V]) clonePath(key []byte) *ImmutableTree[V] {
+	newTree := &ImmutableTree[V]{
+		tree: t.tree,
+	}
+	newTree.tree.root = t.tree.root.clonePath(key, nil)
+	return newTree
+}
+
+// Insert returns a new tree with given key and value inserted, plus the previous value and
+// a boolean to indicate whether the insertion is successful.
+// If the insertion fails, the original tree is returned.
+func (t *ImmutableTree[V]) Insert(key []byte, value V) (
+	newTree *ImmutableTree[V], oldValue V, ok bool) {
+
+	if key == nil {
+		return t, oldValue, false
+	}
+	newTree = t.clonePath(key)
+	// The versions of tree share nodes, so they can't share an arena. Copy the key alone.
+	oldValue, _ = newTree.tree.insert(bytes.Clone(key), value)
+	return newTree, oldValue, true
+}
+
+// Remove returns a new tree without given key, plus the removed value and a boolean to
+// indicate whether the key is found. If the key is not found, the original tree is returned.
+func (t *ImmutableTree[V]) Remove(key []byte) (newTree *ImmutableTree[V], oldValue V, found bool) {
+	if key == nil || len(t.tree.root.edges) == 0 {
+		return t, oldValue, false
+	}
+	newTree = t.clonePath(key)
+	oldValue, found = newTree.tree.Remove(key)
+	if !found {
+		return t, oldValue, false
+	}
+	return newTree, oldValue, true
+}
+
+// RemoveSuffix returns a new tree without the keys which have given suffix, plus the number
+// of removed keys. If no key is removed, the original tree is returned.
+func (t *ImmutableTree[V]) RemoveSuffix(suffix []byte) (newTree *ImmutableTree[V], removed int) {
+	if t.tree.CountSuffix(suffix) == 0 {
+		return t, 0
+	}
+	newTree = t.clonePath(suffix)
+	return newTree, newTree.tree.RemoveSuffix(suffix)
+}
+
+// Get returns the value of given key and a boolean to indicate
+// whether the value is found.
+func (t *ImmutableTree[V]) Get(key []byte) (value V, found bool) {
+	return t.tree.Get(key)
+}
+
+// LongestSuffix returns the key which is the longest suffix of the given key,
+// and the value referred by this key. See Tree.LongestSuffix.
+func (t *ImmutableTree[V]) LongestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	return t.tree.LongestSuffix(key)
+}
+
+// ShortestSuffix returns the key which is the shortest suffix of the given key,
+// and the value referred by this key. See Tree.ShortestSuffix.
+func (t *ImmutableTree[V]) ShortestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	return t.tree.ShortestSuffix(key)
+}
+
+// WalkSuffixMatches calls function with every key which is a suffix of the given key.
+// See Tree.WalkSuffixMatches.
+func (t *ImmutableTree[V]) WalkSuffixMatches(key []byte, order MatchOrder,
+	f func(matchedKey []byte, value V) bool) {
+
+	t.tree.WalkSuffixMatches(key, order, f)
+}
+
+// AllSuffixMatches returns an iterator over every key which is a suffix of the given key.
+// See Tree.AllSuffixMatches.
+func (t *ImmutableTree[V]) AllSuffixMatches(key []byte, order MatchOrder) iter.Seq2[[]byte, V] {
+	return t.tree.AllSuffixMatches(key, order)
+}
+
+// Len returns the number of keys.
+func (t *ImmutableTree[V]) Len() int {
+	return t.tree.Len()
+}
+
+// CountSuffix returns the number of keys which have given suffix. See Tree.CountSuffix.
+func (t *ImmutableTree[V]) CountSuffix(suffix []byte) int {
+	return t.tree.CountSuffix(suffix)
+}
+
+// Walk through the tree, call function with key and value. See Tree.Walk.
+func (t *ImmutableTree[V]) Walk(f func(key []byte, value V) bool) {
+	t.tree.Walk(f)
+}
+
+// WalkSuffix travels through nodes which have given suffix. See Tree.WalkSuffix.
+func (t *ImmutableTree[V]) WalkSuffix(suffix []byte, f func(key []byte, value V) bool) {
+	t.tree.WalkSuffix(suffix, f)
+}
+
+// All returns an iterator over all keys and values in the tree. See Tree.All.
+func (t *ImmutableTree[V]) All() iter.Seq2[[]byte, V] {
+	return t.tree.All()
+}
+
+// Keys returns an iterator over all keys in the tree. See Tree.Keys.
+func (t *ImmutableTree[V]) Keys() iter.Seq[[]byte] {
+	return t.tree.Keys()
+}
+
+// Values returns an iterator over all values in the tree. See Tree.Values.
+func (t *ImmutableTree[V]) Values() iter.Seq[V] {
+	return t.tree.Values()
+}
+
+// WithSuffix returns an iterator over keys which have given suffix and their values.
+// See Tree.WithSuffix.
+func (t *ImmutableTree[V]) WithSuffix(suffix []byte) iter.Seq2[[]byte, V] {
+	return t.tree.WithSuffix(suffix)
+}
@@ -0,0 +1,162 @@
+package suffix
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertTreeContent(t *testing.T, tree *ImmutableTree[string], expected map[string]bool) {
+	assert.Equal(t, len(expected), tree.Len())
+	walked := map[string]bool{}
+	tree.Walk(func(key []byte, value string) bool {
+		assert.Equal(t, string(key), value)
+		walked[string(key)] = true
+		return false
+	})
+	assert.Equal(t, expected, walked)
+	assertNodeLeavesNum(t, tree.tree.root)
+	for key := range expected {
+		value, found := tree.Get([]byte(key))
+		assert.True(t, found, "key %s", key)
+		assert.Equal(t, key, value)
+	}
+}
+
+func TestImmutableTree_Base(t *testing.T) {
+	v0 := NewImmutableTree[string]()
+	v1, _, ok := v0.Insert([]byte("able"), "able")
+	assert.True(t, ok)
+	v2, _, _ := v1.Insert([]byte("table"), "table")
+	v3, oldValue, _ := v2.Insert([]byte("able"), "ABLE")
+	assert.Equal(t, "able", oldValue)
+	v4, oldValue, found := v3.Remove([]byte("table"))
+	assert.True(t, found)
+	assert.Equal(t, "table", oldValue)
+
+	assert.Equal(t, 0, v0.Len())
+	assertTreeContent(t, v1, map[string]bool{"able": true})
+	assertTreeContent(t, v2, map[string]bool{"able": true, "table": true})
+	value, _ := v3.Get([]byte("able"))
+	assert.Equal(t, "ABLE", value)
+	assert.Equal(t, 2, v3.Len())
+	matchedKey, _, _ := v3.LongestSuffix([]byte("presentable"))
+	assert.Equal(t, "table", string(matchedKey))
+	assert.Equal(t, 1, v4.Len())
+	_, found = v4.Get([]byte("table"))
+	assert.False(t, found)
+
+	v5, _, found := v4.Remove([]byte("unknown"))
+	assert.False(t, found)
+	assert.True(t, v4 == v5)
+	v5, _, found = v0.Remove([]byte("able"))
+	assert.False(t, found)
+	assert.True(t, v0 == v5)
+	v5, _, ok = v4.Insert(nil, "")
+	assert.False(t, ok)
+	assert.True(t, v4 == v5)
+}
+
+func TestImmutableTree_CopiesKey(t *testing.T) {
+	v0 := NewImmutableTree[string]()
+	key := []byte("able")
+	v1, _, _ := v0.Insert(key, "able")
+	copy(key, "xxxx")
+	v2, _, _ := v1.Insert([]byte("table"), "table")
+
+	txn := v2.Txn()
+	key = []byte("cable")
+	txn.Insert(key, "cable")
+	v3 := txn.Commit()
+	copy(key, "xxxxx")
+	txn.Insert([]byte("fable"), "fable")
+	other := v3.Txn()
+	other.Insert([]byte("sable"), "sable")
+
+	assertTreeContent(t, v1, map[string]bool{"able": true})
+	assertTreeContent(t, v2, map[string]bool{"able": true, "table": true})
+	assertTreeContent(t, v3, map[string]bool{"able": true, "table": true, "cable": true})
+	assertTreeContent(t, txn.Commit(),
+		map[string]bool{"able": true, "table": true, "cable": true, "fable": true})
+	assertTreeContent(t, other.Commit(),
+		map[string]bool{"able": true, "table": true, "cable": true, "sable": true})
+}
+
+func TestImmutableTree_RemoveSuffix(t *testing.T) {
+	v0 := NewImmutableTree[string]()
+	for _, word := range []string{"able", "table", "stable", "cable", "bale"} {
+		v0, _, _ = v0.Insert([]byte(word), word)
+	}
+	v1, removed := v0.RemoveSuffix([]byte("table"))
+	assert.Equal(t, 2, removed)
+	v2, removed := v1.RemoveSuffix([]byte("xyz"))
+	assert.Equal(t, 0, removed)
+	assert.True(t, v1 == v2)
+
+	assertTreeContent(t, v0, map[string]bool{
+		"able": true, "table": true, "stable": true, "cable": true, "bale": true})
+	assertTreeContent(t, v1, map[string]bool{"able": true, "cable": true, "bale": true})
+
+	txn := v0.Txn()
+	assert.Equal(t, 4, txn.RemoveSuffix([]byte("able")))
+	assert.Equal(t, 0, txn.RemoveSuffix([]byte("able")))
+	assertTreeContent(t, txn.Commit(), map[string]bool{"bale": true})
+	assert.Equal(t, 5, v0.Len())
+}
+
+func TestImmutableTree_StructuralSharing(t *testing.T) {
+	tree := NewImmutableTree[string]()
+	for _, s := range []string{"able", "table", "word", "sword", "thing", "nothing"} {
+		tree, _, _ = tree.Insert([]byte(s), s)
+	}
+	newTree, _, _ := tree.Insert([]byte("unbelievable"), "unbelievable")
+
+	findChild := func(tree *ImmutableTree[string], label string) interface{} {
+		for _, edge := range tree.tree.root.edges {
+			if string(edge.label) == label {
+				return edge.point
+			}
+		}
+		return nil
+	}
+	for _, label := range []string{"word", "thing"} {
+		point := findChild(tree, label)
+		assert.NotNil(t, point)
+		assert.True(t, point == findChild(newTree, label), "subtree %s is not shared", label)
+	}
+	assert.False(t, findChild(tree, "able") == findChild(newTree, "able"))
+	assert.False(t, tree.tree.root == newTree.tree.root)
+}
+
+func TestImmutableTree_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	words := []string{}
+	for i := 0; i < 128; i++ {
+		words = append(words, string(randomKey(r, letters, 0, 7)))
+	}
+
+	versions := []*ImmutableTree[string]{NewImmutableTree[string]()}
+	expected := []map[string]bool{{}}
+	for i := 0; i < 1024; i++ {
+		tree := versions[len(versions)-1]
+		content := map[string]bool{}
+		for k := range expected[len(expected)-1] {
+			content[k] = true
+		}
+		word := words[r.Intn(len(words))]
+		if r.Intn(3) == 0 {
+			tree, _, _ = tree.Remove([]byte(word))
+			delete(content, word)
+		} else {
+			tree, _, _ = tree.Insert([]byte(word), word)
+			content[word] = true
+		}
+		versions = append(versions, tree)
+		expected = append(expected, content)
+	}
+	for i, tree := range versions {
+		assertTreeContent(t, tree, expected[i])
+	}
+}
@@ -0,0 +1,115 @@
+package suffix
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// PSLSection is the section of the Public Suffix List which a rule belongs to.
+type PSLSection int
+
+const (
+	// ICANNSection contains the domains delegated by ICANN.
+	ICANNSection PSLSection = iota
+	// PrivateSection contains the domains submitted by their owners.
+	PrivateSection
+)
+
+var (
+	pslComment      = []byte("//")
+	pslBeginICANN   = []byte("===BEGIN ICANN DOMAINS===")
+	pslEndICANN     = []byte("===END ICANN DOMAINS===")
+	pslBeginPrivate = []byte("===BEGIN PRIVATE DOMAINS===")
+	pslEndPrivate   = []byte("===END PRIVATE DOMAINS===")
+)
+
+// PublicSuffixList is the Public Suffix List (https://publicsuffix.org/) stored in a RuleTree.
+type PublicSuffixList struct {
+	rules *RuleTree[PSLSection]
+}
+
+// ParsePublicSuffixList reads a list in the format of public_suffix_list.dat.
+// Comments, blank lines, wildcard rules and exception rules are supported.
+// Rules outside the ICANN section are treated as private rules.
+func ParsePublicSuffixList(r io.Reader) (*PublicSuffixList, error) {
+	list := &PublicSuffixList{
+		rules: NewRuleTree[PSLSection](),
+	}
+	section := PrivateSection
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if bytes.HasPrefix(line, pslComment) {
+			comment := bytes.TrimSpace(line[len(pslComment):])
+			switch {
+			case bytes.Equal(comment, pslBeginICANN):
+				section = ICANNSection
+			case bytes.Equal(comment, pslEndICANN), bytes.Equal(comment, pslBeginPrivate),
+				bytes.Equal(comment, pslEndPrivate):
+
+				section = PrivateSection
+			}
+			continue
+		}
+		// Each line is only read up to the first whitespace
+		if i := bytes.IndexAny(line, " \t"); i != -1 {
+			line = line[:i]
+		}
+		// The scanner reuses its buffer, so the rule should be copied
+		rule := bytes.ToLower(line)
+		if _, ok := list.rules.Insert(rule, section); !ok {
+			return nil, fmt.Errorf("suffix: invalid rule %q at line %d", line, lineNo)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Len returns the number of rules in the list.
+func (list *PublicSuffixList) Len() int {
+	return list.rules.Len()
+}
+
+// PublicSuffix returns the public suffix of the domain, and a boolean to indicate whether
+// the public suffix is managed by ICANN. When no rule matches, the prevailing rule is "*",
+// so the public suffix is the last label of the domain.
+// The domain is expected to be in lower case.
+func (list *PublicSuffixList) PublicSuffix(domain []byte) (publicSuffix []byte, icann bool) {
+	match, found := list.rules.Match(domain)
+	if !found {
+		return domain[bytes.LastIndexByte(domain, '.')+1:], false
+	}
+	publicSuffix = match.Matched
+	if match.Kind == ExceptionRule {
+		// Remove the leftmost label of the exception rule
+		publicSuffix = publicSuffix[bytes.IndexByte(publicSuffix, '.')+1:]
+	}
+	return publicSuffix, match.Value == ICANNSection
+}
+
+// EffectiveTLDPlusOne returns the public suffix of the domain plus one more label.
+// For example, the eTLD+1 of "a.b.example.co.uk" is "example.co.uk".
+// It returns an error if the domain has empty label or the domain is a public suffix.
+// The domain is expected to be in lower case.
+func (list *PublicSuffixList) EffectiveTLDPlusOne(domain []byte) ([]byte, error) {
+	if len(domain) == 0 || domain[0] == '.' || domain[len(domain)-1] == '.' ||
+		bytes.Contains(domain, []byte("..")) {
+
+		return nil, fmt.Errorf("suffix: empty label in domain %q", domain)
+	}
+	publicSuffix, _ := list.PublicSuffix(domain)
+	if len(publicSuffix) >= len(domain) {
+		return nil, fmt.Errorf("suffix: cannot derive eTLD+1 for domain %q", domain)
+	}
+	rest := domain[:len(domain)-len(publicSuffix)-1]
+	return domain[bytes.LastIndexByte(rest, '.')+1:], nil
+}
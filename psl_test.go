@@ -0,0 +1,127 @@
+package suffix
+
+import (
+	"bytes"
+	_ "embed"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//go:embed testdata/public_suffix_list.dat
+var publicSuffixListData []byte
+
+func getPublicSuffixList(t *testing.T) *PublicSuffixList {
+	list, err := ParsePublicSuffixList(bytes.NewReader(publicSuffixListData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestParsePublicSuffixList(t *testing.T) {
+	list := getPublicSuffixList(t)
+	assert.Equal(t, 52, list.Len())
+
+	section, found := list.rules.Get([]byte("*.kobe.jp"))
+	assert.True(t, found)
+	assert.Equal(t, ICANNSection, section)
+	section, found = list.rules.Get([]byte("!www.compute.example.com"))
+	assert.True(t, found)
+	assert.Equal(t, PrivateSection, section)
+
+	list, err := ParsePublicSuffixList(strings.NewReader("Com  // trailing text\n\tnet\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, list.Len())
+	_, found = list.rules.Get([]byte("com"))
+	assert.True(t, found)
+
+	_, err = ParsePublicSuffixList(strings.NewReader("com\n\n*.*.com\n"))
+	assert.EqualError(t, err, `suffix: invalid rule "*.*.com" at line 3`)
+}
+
+func TestPublicSuffix(t *testing.T) {
+	list := getPublicSuffixList(t)
+	cases := []struct {
+		domain       string
+		publicSuffix string
+		icann        bool
+	}{
+		{"com", "com", true},
+		{"example.com", "com", true},
+		{"www.example.com", "com", true},
+		{"example", "example", false},
+		{"www.example.example", "example", false},
+		{"uk", "uk", true},
+		{"example.co.uk", "co.uk", true},
+		{"b.example.co.uk", "co.uk", true},
+		{"a.b.sch.uk", "b.sch.uk", true},
+		{"c.kobe.jp", "c.kobe.jp", true},
+		{"b.c.kobe.jp", "c.kobe.jp", true},
+		{"city.kobe.jp", "kobe.jp", true},
+		{"www.city.kobe.jp", "kobe.jp", true},
+		{"www.ck", "ck", true},
+		{"www.www.ck", "ck", true},
+		{"test.ck", "test.ck", true},
+		{"mm", "mm", false},
+		{"c.mm", "c.mm", true},
+		{"foo.blogspot.com", "blogspot.com", false},
+		{"blogspot.co.uk", "blogspot.co.uk", false},
+		{"a.b.compute.example.com", "b.compute.example.com", false},
+		{"www.compute.example.com", "compute.example.com", false},
+		{"notgithub.io", "io", false},
+		{"a.公司", "公司", true},
+	}
+	for _, c := range cases {
+		publicSuffix, icann := list.PublicSuffix([]byte(c.domain))
+		assert.Equal(t, c.publicSuffix, string(publicSuffix), "domain %s", c.domain)
+		assert.Equal(t, c.icann, icann, "domain %s", c.domain)
+	}
+}
+
+func TestEffectiveTLDPlusOne(t *testing.T) {
+	list := getPublicSuffixList(t)
+	cases := []struct {
+		domain   string
+		expected string
+	}{
+		// Some of these cases come from the test data of the Public Suffix List
+		{"com", ""},
+		{"example.com", "example.com"},
+		{"b.example.com", "example.com"},
+		{"a.b.example.com", "example.com"},
+		{"uk.com", "uk.com"},
+		{"biz", ""},
+		{"domain.biz", "domain.biz"},
+		{"c.kobe.jp", ""},
+		{"b.c.kobe.jp", "b.c.kobe.jp"},
+		{"a.b.c.kobe.jp", "b.c.kobe.jp"},
+		{"city.kobe.jp", "city.kobe.jp"},
+		{"www.city.kobe.jp", "city.kobe.jp"},
+		{"ck", ""},
+		{"test.ck", ""},
+		{"b.test.ck", "b.test.ck"},
+		{"www.ck", "www.ck"},
+		{"www.www.ck", "www.ck"},
+		{"k12.ak.us", ""},
+		{"test.k12.ak.us", "test.k12.ak.us"},
+		{"www.test.k12.ak.us", "test.k12.ak.us"},
+		{"mm", ""},
+		{"c.mm", ""},
+		{"b.c.mm", "b.c.mm"},
+		{"foo.github.io", "foo.github.io"},
+		{"", ""},
+		{".com", ""},
+		{"example.com.", ""},
+		{"a..example.com", ""},
+	}
+	for _, c := range cases {
+		etldPlusOne, err := list.EffectiveTLDPlusOne([]byte(c.domain))
+		if c.expected == "" {
+			assert.NotNil(t, err, "domain %s", c.domain)
+		} else if assert.Nil(t, err, "domain %s", c.domain) {
+			assert.Equal(t, c.expected, string(etldPlusOne), "domain %s", c.domain)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package suffix
+
+import (
+	"bytes"
+	"math/bits"
+	"sort"
+)
+
+// Repeat is a substring which occurs more than once in the keys of SubstringIndex.
+type Repeat struct {
+	Substring []byte
+	// The number of occurrences
+	Count int
+}
+
+// The result of traveling through the nodes of SubstringIndex.
+type _SubstringStats struct {
+	// Nodes in pre-order, so a node always comes after its parent
+	order   []int32
+	parents []int32
+	// The length of the string from root to the node
+	depths []int32
+	// The first position in text where the string from root to the node occurs
+	firsts []int32
+}
+
+// The tree could be as deep as the text, so travel it without recursion.
+func (idx *SubstringIndex) stats() *_SubstringStats {
+	nodesNum := len(idx.nodes)
+	stats := &_SubstringStats{
+		order:   make([]int32, 0, nodesNum),
+		parents: make([]int32, nodesNum),
+		depths:  make([]int32, nodesNum),
+		firsts:  make([]int32, nodesNum),
+	}
+	stack := []int32{substringRoot}
+	stats.parents[substringRoot] = -1
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		stats.order = append(stats.order, node)
+		if idx.isLeaf(node) {
+			stats.firsts[node] = idx.nodes[node].suffix
+			continue
+		}
+		stats.firsts[node] = int32(len(idx.text))
+		for _, child := range idx.nodes[node].children {
+			stats.parents[child] = node
+			stats.depths[child] = stats.depths[node] + idx.edgeLength(child)
+			stack = append(stack, child)
+		}
+	}
+	// Children before parents
+	for i := len(stats.order) - 1; i > 0; i-- {
+		node := stats.order[i]
+		parent := stats.parents[node]
+		if stats.firsts[node] < stats.firsts[parent] {
+			stats.firsts[parent] = stats.firsts[node]
+		}
+	}
+	return stats
+}
+
+func (idx *SubstringIndex) substring(start, length int32) []byte {
+	res := make([]byte, length)
+	for i := range res {
+		res[i] = byte(idx.text[start+int32(i)])
+	}
+	return res
+}
+
+// Return the deepest internal node accepted by f, and the earliest one if there are several.
+func (idx *SubstringIndex) deepest(stats *_SubstringStats, f func(node int32) bool) []byte {
+	best := int32(substringRoot)
+	for _, node := range stats.order {
+		if node == substringRoot || idx.isLeaf(node) || !f(node) {
+			continue
+		}
+		if stats.depths[node] > stats.depths[best] ||
+			(stats.depths[node] == stats.depths[best] && stats.firsts[node] < stats.firsts[best]) {
+			best = node
+		}
+	}
+	if best == substringRoot {
+		return []byte{}
+	}
+	return idx.substring(stats.firsts[best], stats.depths[best])
+}
+
+// LongestRepeatedSubstring returns the longest substring which occurs at least twice in the
+// key with given id. The occurrences may overlap. If there are several ones, the one which
+// occurs first is returned. Return an empty slice if no byte is repeated.
+func (idx *SubstringIndex) LongestRepeatedSubstring(id int) []byte {
+	stats := idx.stats()
+	counts := make([]int, len(idx.nodes))
+	for i := len(stats.order) - 1; i > 0; i-- {
+		node := stats.order[i]
+		if idx.isLeaf(node) && idx.nodes[node].key == int32(id) {
+			counts[node] = 1
+		}
+		counts[stats.parents[node]] += counts[node]
+	}
+	return idx.deepest(stats, func(node int32) bool {
+		return counts[node] >= 2
+	})
+}
+
+// LongestCommonSubstring returns the longest substring which occurs in all the keys with
+// given ids. If there are several ones, the one which occurs first is returned.
+// Return an empty slice if there is no common byte, or nil if no id is given.
+func (idx *SubstringIndex) LongestCommonSubstring(ids ...int) []byte {
+	if len(ids) == 0 {
+		return nil
+	}
+	// Mark the keys covered by each node in a bitset
+	bitOfKey := map[int32]int{}
+	for _, id := range ids {
+		if _, ok := bitOfKey[int32(id)]; !ok {
+			bitOfKey[int32(id)] = len(bitOfKey)
+		}
+	}
+	if len(bitOfKey) == 1 {
+		return idx.Key(ids[0])
+	}
+	words := (len(bitOfKey) + 63) / 64
+	sets := make([]uint64, len(idx.nodes)*words)
+	stats := idx.stats()
+	for i := len(stats.order) - 1; i > 0; i-- {
+		node := stats.order[i]
+		set := sets[int(node)*words : int(node+1)*words]
+		if idx.isLeaf(node) {
+			if bit, ok := bitOfKey[idx.nodes[node].key]; ok {
+				set[bit/64] |= 1 << (bit % 64)
+			}
+		}
+		parentSet := sets[int(stats.parents[node])*words:]
+		for j, word := range set {
+			parentSet[j] |= word
+		}
+	}
+	return idx.deepest(stats, func(node int32) bool {
+		covered := 0
+		for _, word := range sets[int(node)*words : int(node+1)*words] {
+			covered += bits.OnesCount64(word)
+		}
+		return covered == len(bitOfKey)
+	})
+}
+
+// MaximalRepeats returns the substrings not shorter than minLen, which occur at least twice
+// in the keys, and can't be extended in either direction without losing an occurrence.
+// The repeats are ordered from the longest to the shortest, and then lexicographically.
+func (idx *SubstringIndex) MaximalRepeats(minLen int) []Repeat {
+	if minLen < 1 {
+		minLen = 1
+	}
+	stats := idx.stats()
+	counts := make([]int, len(idx.nodes))
+	// The byte before all the occurrences, or -1 if they are preceded by different bytes
+	// or the beginning of a key. As a branching node, the string from root to an internal
+	// node is always right maximal, so it is maximal if the preceding bytes are different.
+	lefts := make([]int32, len(idx.nodes))
+	for i := range lefts {
+		lefts[i] = -2
+	}
+	const diverse = -1
+	for i := len(stats.order) - 1; i > 0; i-- {
+		node := stats.order[i]
+		if idx.isLeaf(node) {
+			counts[node] = 1
+			leaf := &idx.nodes[node]
+			if leaf.suffix == idx.keyStarts[leaf.key] {
+				lefts[node] = diverse
+			} else {
+				lefts[node] = idx.text[leaf.suffix-1]
+			}
+		}
+		parent := stats.parents[node]
+		counts[parent] += counts[node]
+		if lefts[parent] == -2 {
+			lefts[parent] = lefts[node]
+		} else if lefts[parent] != lefts[node] {
+			lefts[parent] = diverse
+		}
+	}
+
+	repeats := []Repeat{}
+	for _, node := range stats.order {
+		if node == substringRoot || idx.isLeaf(node) || lefts[node] != diverse ||
+			stats.depths[node] < int32(minLen) {
+			continue
+		}
+		repeats = append(repeats, Repeat{
+			Substring: idx.substring(stats.firsts[node], stats.depths[node]),
+			Count:     counts[node],
+		})
+	}
+	sort.Slice(repeats, func(i, j int) bool {
+		if len(repeats[i].Substring) != len(repeats[j].Substring) {
+			return len(repeats[i].Substring) > len(repeats[j].Substring)
+		}
+		return bytes.Compare(repeats[i].Substring, repeats[j].Substring) < 0
+	})
+	return repeats
+}
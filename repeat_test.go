@@ -0,0 +1,138 @@
+package suffix
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLongestRepeatedSubstring(t *testing.T) {
+	idx := NewSubstringIndex()
+	idx.Add([]byte("banana"))
+	idx.Add([]byte("abcd"))
+	idx.Add([]byte("abcabcd"))
+	idx.Add([]byte(""))
+	assert.Equal(t, "ana", string(idx.LongestRepeatedSubstring(0)))
+	// "abc" occurs in other keys, but only once in this one
+	assert.Equal(t, "", string(idx.LongestRepeatedSubstring(1)))
+	assert.Equal(t, "abc", string(idx.LongestRepeatedSubstring(2)))
+	assert.Equal(t, "", string(idx.LongestRepeatedSubstring(3)))
+}
+
+func TestLongestCommonSubstring(t *testing.T) {
+	idx := NewSubstringIndex()
+	idx.Add([]byte("GET /api/v1/users 200"))
+	idx.Add([]byte("POST /api/v1/users 201"))
+	idx.Add([]byte("GET /api/v2/users 200"))
+	idx.Add([]byte("xyz"))
+	assert.Equal(t, "T /api/v1/users 20", string(idx.LongestCommonSubstring(0, 1)))
+	assert.Equal(t, "/users 20", string(idx.LongestCommonSubstring(0, 1, 2)))
+	assert.Equal(t, "GET /api/v", string(idx.LongestCommonSubstring(0, 2, 0)))
+	assert.Equal(t, "", string(idx.LongestCommonSubstring(0, 3)))
+	assert.Equal(t, "xyz", string(idx.LongestCommonSubstring(3)))
+	assert.Nil(t, idx.LongestCommonSubstring())
+}
+
+func TestMaximalRepeats(t *testing.T) {
+	idx := NewSubstringIndex()
+	idx.Add([]byte("xabcyabcz"))
+	idx.Add([]byte("abc"))
+	repeats := idx.MaximalRepeats(2)
+	assert.Equal(t, []Repeat{{[]byte("abc"), 3}}, repeats)
+	repeats = idx.MaximalRepeats(0)
+	assert.Equal(t, []Repeat{{[]byte("abc"), 3}}, repeats)
+	assert.Equal(t, 0, len(idx.MaximalRepeats(4)))
+}
+
+func occurrencesOf(keys [][]byte, s []byte) (count int, lefts, rights map[int]bool) {
+	lefts = map[int]bool{}
+	rights = map[int]bool{}
+	for _, key := range keys {
+		for i := 0; i+len(s) <= len(key); i++ {
+			if !bytes.HasPrefix(key[i:], s) {
+				continue
+			}
+			count++
+			// The beginning and the end of keys are unique, so use a distinct negative number
+			if i == 0 {
+				lefts[-1-count] = true
+			} else {
+				lefts[int(key[i-1])] = true
+			}
+			if j := i + len(s); j == len(key) {
+				rights[-1-count] = true
+			} else {
+				rights[int(key[j])] = true
+			}
+		}
+	}
+	return count, lefts, rights
+}
+
+func TestRepeats_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("ab")
+	for turn := 0; turn < 50; turn++ {
+		idx := NewSubstringIndex()
+		keys := [][]byte{}
+		for i := 0; i < 4; i++ {
+			key := randomKey(r, letters, 0, 11)
+			keys = append(keys, key)
+			idx.Add(key)
+		}
+
+		substrings := map[string]bool{}
+		for _, key := range keys {
+			for i := 0; i < len(key); i++ {
+				for j := i + 1; j <= len(key); j++ {
+					substrings[string(key[i:j])] = true
+				}
+			}
+		}
+		expected := []Repeat{}
+		for s := range substrings {
+			count, lefts, rights := occurrencesOf(keys, []byte(s))
+			if count >= 2 && len(lefts) > 1 && len(rights) > 1 {
+				expected = append(expected, Repeat{[]byte(s), count})
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool {
+			if len(expected[i].Substring) != len(expected[j].Substring) {
+				return len(expected[i].Substring) > len(expected[j].Substring)
+			}
+			return bytes.Compare(expected[i].Substring, expected[j].Substring) < 0
+		})
+		assert.Equal(t, expected, idx.MaximalRepeats(1))
+
+		for id, key := range keys {
+			longest := 0
+			for s := range substrings {
+				if len(s) > longest {
+					if count, _, _ := occurrencesOf([][]byte{key}, []byte(s)); count >= 2 {
+						longest = len(s)
+					}
+				}
+			}
+			repeated := idx.LongestRepeatedSubstring(id)
+			assert.Equal(t, longest, len(repeated))
+			count, _, _ := occurrencesOf([][]byte{key}, repeated)
+			assert.True(t, len(repeated) == 0 || count >= 2)
+		}
+
+		longest := 0
+		for s := range substrings {
+			if len(s) > longest && bytes.Contains(keys[0], []byte(s)) &&
+				bytes.Contains(keys[1], []byte(s)) && bytes.Contains(keys[2], []byte(s)) {
+				longest = len(s)
+			}
+		}
+		common := idx.LongestCommonSubstring(0, 1, 2)
+		assert.Equal(t, longest, len(common))
+		for _, key := range keys[:3] {
+			assert.True(t, bytes.Contains(key, common))
+		}
+	}
+}
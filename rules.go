@@ -0,0 +1,211 @@
+package suffix
+
+import (
+	"bytes"
+)
+
+// RuleKind is the kind of a rule in RuleTree.
+type RuleKind int
+
+const (
+	// NormalRule like "example.com" matches the domain itself and all its subdomains.
+	NormalRule RuleKind = iota
+	// WildcardRule like "*.example.com" matches exactly one extra label before the rest of rule,
+	// and all subdomains of it.
+	WildcardRule
+	// ExceptionRule like "!www.example.com" overrides any other matched rule.
+	ExceptionRule
+)
+
+var (
+	wildcardPrefix  = []byte("*.")
+	exceptionPrefix = []byte("!")
+)
+
+// RuleMatch describes the rule which wins in RuleTree.Match.
+type RuleMatch[V any] struct {
+	// Rule is the text of the matched rule, in the same format accepted by RuleTree.Insert.
+	Rule []byte
+	Kind RuleKind
+	// Value is the value inserted with the matched rule.
+	Value V
+	// Matched is the suffix of the queried domain matched by the rule. For a wildcard rule,
+	// it includes the label matched by the wildcard.
+	Matched []byte
+	// Labels is the number of labels in the rule, including the wildcard label.
+	Labels int
+}
+
+type _RuleEntry[V any] struct {
+	values [3]V
+	has    [3]bool
+}
+
+func (entry *_RuleEntry[V]) empty() bool {
+	return !entry.has[NormalRule] && !entry.has[WildcardRule] && !entry.has[ExceptionRule]
+}
+
+// RuleTree stores domain rules, including wildcard rules like "*.example.com" and
+// exception rules like "!www.example.com", and resolves a domain to the winning rule
+// with the precedence algorithm of the Public Suffix List:
+//
+//  1. If an exception rule matches, it wins.
+//  2. Otherwise the matched rule with the most labels wins. A normal rule wins over a
+//     wildcard rule with the same number of labels.
+//
+// Labels are separated by '.', and rules only match at the label boundary.
+// The implicit default rule "*" of the Public Suffix List is not stored in the tree.
+type RuleTree[V any] struct {
+	tree     *SeparatorTree[*_RuleEntry[V]]
+	rulesNum int
+}
+
+// NewRuleTree creates a RuleTree for future usage.
+func NewRuleTree[V any]() *RuleTree[V] {
+	return &RuleTree[V]{
+		tree: NewDomainTree[*_RuleEntry[V]](),
+	}
+}
+
+// Split rule into its kind and the domain part. Return false if the rule is invalid.
+func parseRule(rule []byte) (RuleKind, []byte, bool) {
+	kind := NormalRule
+	key := rule
+	if bytes.HasPrefix(key, exceptionPrefix) {
+		kind = ExceptionRule
+		key = key[len(exceptionPrefix):]
+	} else if bytes.HasPrefix(key, wildcardPrefix) {
+		kind = WildcardRule
+		key = key[len(wildcardPrefix):]
+	}
+	// Only a leading wildcard label is supported
+	if len(key) == 0 || key[0] == '.' || bytes.IndexByte(key, '*') != -1 ||
+		bytes.IndexByte(key, '!') != -1 {
+
+		return kind, nil, false
+	}
+	return kind, key, true
+}
+
+func formatRule(kind RuleKind, key []byte) []byte {
+	switch kind {
+	case WildcardRule:
+		return append(append([]byte{}, wildcardPrefix...), key...)
+	case ExceptionRule:
+		return append(append([]byte{}, exceptionPrefix...), key...)
+	}
+	return key
+}
+
+func countLabels(domain []byte) int {
+	return bytes.Count(domain, []byte{'.'}) + 1
+}
+
+// Insert rule with given value. Return the previous value of this rule and a boolean to
+// indicate whether the insertion is successful. The insertion fails if the rule is invalid.
+func (rt *RuleTree[V]) Insert(rule []byte, value V) (oldValue V, ok bool) {
+	kind, key, ok := parseRule(rule)
+	if !ok {
+		return oldValue, false
+	}
+	entry, found := rt.tree.Get(key)
+	if !found {
+		entry = &_RuleEntry[V]{}
+		rt.tree.Insert(key, entry)
+	}
+	oldValue = entry.values[kind]
+	if !entry.has[kind] {
+		entry.has[kind] = true
+		rt.rulesNum++
+	}
+	entry.values[kind] = value
+	return oldValue, true
+}
+
+// Get returns the value of given rule and a boolean to indicate
+// whether the rule is found.
+func (rt *RuleTree[V]) Get(rule []byte) (value V, found bool) {
+	kind, key, ok := parseRule(rule)
+	if !ok {
+		return value, false
+	}
+	entry, found := rt.tree.Get(key)
+	if !found || !entry.has[kind] {
+		return value, false
+	}
+	return entry.values[kind], true
+}
+
+// Remove returns the value of given rule and a boolean to indicate
+// whether the rule is found. Then the rule will be removed.
+func (rt *RuleTree[V]) Remove(rule []byte) (oldValue V, found bool) {
+	kind, key, ok := parseRule(rule)
+	if !ok {
+		return oldValue, false
+	}
+	entry, found := rt.tree.Get(key)
+	if !found || !entry.has[kind] {
+		return oldValue, false
+	}
+	oldValue = entry.values[kind]
+	var zero V
+	entry.values[kind] = zero
+	entry.has[kind] = false
+	rt.rulesNum--
+	if entry.empty() {
+		rt.tree.Remove(key)
+	}
+	return oldValue, true
+}
+
+// Len returns the number of rules.
+func (rt *RuleTree[V]) Len() int {
+	return rt.rulesNum
+}
+
+// Match returns the rule which wins for given domain, and a boolean to indicate
+// whether any rule matches.
+func (rt *RuleTree[V]) Match(domain []byte) (match RuleMatch[V], found bool) {
+	if len(domain) == 0 {
+		return match, false
+	}
+	var winner *_RuleEntry[V]
+	var winnerKey []byte
+	winnerKind := NormalRule
+	winnerLabels := 0
+	rt.tree.WalkSuffixMatches(domain, LongestFirst, func(key []byte, entry *_RuleEntry[V]) bool {
+		if entry.has[ExceptionRule] {
+			// The longest exception rule wins
+			winner, winnerKey, winnerKind = entry, key, ExceptionRule
+			winnerLabels = countLabels(key)
+			return true
+		}
+		labels := countLabels(key)
+		if entry.has[WildcardRule] && len(domain) > len(key) && labels+1 > winnerLabels {
+			winner, winnerKey, winnerKind = entry, key, WildcardRule
+			winnerLabels = labels + 1
+		}
+		if entry.has[NormalRule] && labels > winnerLabels {
+			winner, winnerKey, winnerKind = entry, key, NormalRule
+			winnerLabels = labels
+		}
+		return false
+	})
+	if winner == nil {
+		return match, false
+	}
+
+	matched := domain[len(domain)-len(winnerKey):]
+	if winnerKind == WildcardRule {
+		// Include the label matched by the wildcard
+		rest := domain[:len(domain)-len(winnerKey)-1]
+		matched = domain[bytes.LastIndexByte(rest, '.')+1:]
+	}
+	return RuleMatch[V]{
+		Rule:    formatRule(winnerKind, winnerKey),
+		Kind:    winnerKind,
+		Value:   winner.values[winnerKind],
+		Matched: matched,
+		Labels:  winnerLabels,
+	}, true
+}
@@ -0,0 +1,116 @@
+package suffix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertRuleMatch(t *testing.T, rt *RuleTree[string], domain string,
+	expectedRule string, expectedMatched string) {
+
+	match, found := rt.Match([]byte(domain))
+	if expectedRule == "" {
+		assert.False(t, found, "domain %s matched %s", domain, string(match.Rule))
+		return
+	}
+	if assert.True(t, found, "domain %s", domain) {
+		assert.Equal(t, expectedRule, string(match.Rule), "domain %s", domain)
+		assert.Equal(t, expectedRule, match.Value, "domain %s", domain)
+		assert.Equal(t, expectedMatched, string(match.Matched), "domain %s", domain)
+	}
+}
+
+func TestRuleTree_InvalidRule(t *testing.T) {
+	rt := NewRuleTree[string]()
+	for _, rule := range []string{"", "*", "!", "*.", "!*.ck", "a.*.ck", ".ck", "*.*.ck", "!!ck"} {
+		_, ok := rt.Insert([]byte(rule), rule)
+		assert.False(t, ok, "rule %s", rule)
+		_, found := rt.Get([]byte(rule))
+		assert.False(t, found)
+		_, found = rt.Remove([]byte(rule))
+		assert.False(t, found)
+	}
+	assert.Equal(t, 0, rt.Len())
+}
+
+func TestRuleTree_Base(t *testing.T) {
+	rt := NewRuleTree[string]()
+	for _, rule := range []string{"ck", "*.ck", "!www.ck"} {
+		oldValue, ok := rt.Insert([]byte(rule), rule)
+		assert.True(t, ok)
+		assert.Equal(t, "", oldValue)
+	}
+	assert.Equal(t, 3, rt.Len())
+	oldValue, _ := rt.Insert([]byte("*.ck"), "*.ck")
+	assert.Equal(t, "*.ck", oldValue)
+	assert.Equal(t, 3, rt.Len())
+
+	for _, rule := range []string{"ck", "*.ck", "!www.ck"} {
+		value, found := rt.Get([]byte(rule))
+		assert.True(t, found)
+		assert.Equal(t, rule, value)
+	}
+	_, found := rt.Get([]byte("www.ck"))
+	assert.False(t, found)
+	_, found = rt.Get([]byte("!ck"))
+	assert.False(t, found)
+
+	value, found := rt.Remove([]byte("*.ck"))
+	assert.True(t, found)
+	assert.Equal(t, "*.ck", value)
+	assert.Equal(t, 2, rt.Len())
+	_, found = rt.Get([]byte("*.ck"))
+	assert.False(t, found)
+	_, found = rt.Get([]byte("ck"))
+	assert.True(t, found)
+
+	rt.Remove([]byte("ck"))
+	rt.Remove([]byte("!www.ck"))
+	assert.Equal(t, 0, rt.Len())
+	assert.Equal(t, 0, rt.tree.Len())
+}
+
+func TestRuleTree_Match(t *testing.T) {
+	rt := NewRuleTree[string]()
+	for _, rule := range []string{
+		"com", "example.com", "*.cdn.example.com", "www.cdn.example.com",
+		"*.ck", "!www.ck", "jp", "*.kobe.jp", "!city.kobe.jp",
+	} {
+		rt.Insert([]byte(rule), rule)
+	}
+
+	assertRuleMatch(t, rt, "org", "", "")
+	assertRuleMatch(t, rt, "notcom", "", "")
+	assertRuleMatch(t, rt, "com", "com", "com")
+	assertRuleMatch(t, rt, "sample.com", "com", "com")
+	assertRuleMatch(t, rt, "a.b.example.com", "example.com", "example.com")
+	// The wildcard requires one extra label
+	assertRuleMatch(t, rt, "cdn.example.com", "example.com", "example.com")
+	assertRuleMatch(t, rt, "img.cdn.example.com", "*.cdn.example.com", "img.cdn.example.com")
+	assertRuleMatch(t, rt, "a.img.cdn.example.com", "*.cdn.example.com", "img.cdn.example.com")
+	// A normal rule wins over a wildcard rule with the same number of labels
+	assertRuleMatch(t, rt, "a.www.cdn.example.com", "www.cdn.example.com", "www.cdn.example.com")
+
+	assertRuleMatch(t, rt, "ck", "", "")
+	assertRuleMatch(t, rt, "a.ck", "*.ck", "a.ck")
+	assertRuleMatch(t, rt, "b.a.ck", "*.ck", "a.ck")
+	assertRuleMatch(t, rt, "www.ck", "!www.ck", "www.ck")
+	assertRuleMatch(t, rt, "a.www.ck", "!www.ck", "www.ck")
+
+	assertRuleMatch(t, rt, "jp", "jp", "jp")
+	assertRuleMatch(t, rt, "kobe.jp", "jp", "jp")
+	assertRuleMatch(t, rt, "c.kobe.jp", "*.kobe.jp", "c.kobe.jp")
+	assertRuleMatch(t, rt, "city.kobe.jp", "!city.kobe.jp", "city.kobe.jp")
+	assertRuleMatch(t, rt, "www.city.kobe.jp", "!city.kobe.jp", "city.kobe.jp")
+
+	match, _ := rt.Match([]byte("a.img.cdn.example.com"))
+	assert.Equal(t, WildcardRule, match.Kind)
+	assert.Equal(t, 4, match.Labels)
+	match, _ = rt.Match([]byte("www.city.kobe.jp"))
+	assert.Equal(t, ExceptionRule, match.Kind)
+	assert.Equal(t, 3, match.Labels)
+
+	_, found := rt.Match([]byte(""))
+	assert.False(t, found)
+}
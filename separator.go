@@ -0,0 +1,160 @@
+package suffix
+
+import (
+	"iter"
+)
+
+// SeparatorTree is a suffix tree which only matches suffixes starting at a label boundary.
+// A label boundary is the start of the whole key, or the position after a separator.
+// For example, with '.' as separator, "example.com" is a suffix of "www.example.com",
+// but not a suffix of "notexample.com".
+// A stored key which starts with the separator, like ".example.com", is always at a
+// label boundary when it matches.
+// Unlike Tree, a stored empty key has no label, so it is only a suffix of the empty key.
+// LongestSuffix, ShortestSuffix and WalkSuffixMatches never return it for other keys.
+type SeparatorTree[V any] struct {
+	tree      *Tree[V]
+	separator byte
+}
+
+// NewSeparatorTree creates a SeparatorTree with the given separator, for example '/' for paths.
+func NewSeparatorTree[V any](separator byte) *SeparatorTree[V] {
+	return &SeparatorTree[V]{
+		tree:      New[V](),
+		separator: separator,
+	}
+}
+
+// NewDomainTree creates a SeparatorTree which uses '.' as separator, for domain names.
+func NewDomainTree[V any]() *SeparatorTree[V] {
+	return NewSeparatorTree[V]('.')
+}
+
+// Separator returns the separator of the tree.
+func (tree *SeparatorTree[V]) Separator() byte {
+	return tree.separator
+}
+
+// Check if suffix, which is a suffix of key, starts at a label boundary of key.
+func (tree *SeparatorTree[V]) atBoundary(key []byte, suffix []byte) bool {
+	if len(suffix) == len(key) {
+		return true
+	}
+	if len(suffix) == 0 {
+		return false
+	}
+	if suffix[0] == tree.separator {
+		return true
+	}
+	return key[len(key)-len(suffix)-1] == tree.separator
+}
+
+// Insert suffix tree with given key and value. Return the previous value and a boolean to
+// indicate whether the insertion is successful.
+func (tree *SeparatorTree[V]) Insert(key []byte, value V) (oldValue V, ok bool) {
+	return tree.tree.Insert(key, value)
+}
+
+// Get returns the value of given key and a boolean to indicate
+// whether the value is found.
+func (tree *SeparatorTree[V]) Get(key []byte) (value V, found bool) {
+	// The whole key is always at the label boundary
+	return tree.tree.Get(key)
+}
+
+// Remove returns the value of given key and a boolean to indicate
+// whethe the value is found. Then the value will be removed.
+func (tree *SeparatorTree[V]) Remove(key []byte) (oldValue V, found bool) {
+	return tree.tree.Remove(key)
+}
+
+// Len returns the number of keys.
+func (tree *SeparatorTree[V]) Len() int {
+	return tree.tree.Len()
+}
+
+// Walk through the tree, call function with key and value.
+// Once the function returns true, it will stop walking.
+// The travelling order is the same as Tree.Walk.
+func (tree *SeparatorTree[V]) Walk(f func(key []byte, value V) bool) {
+	tree.tree.Walk(f)
+}
+
+// All returns an iterator over all keys and values in the tree, in the same order as Walk.
+func (tree *SeparatorTree[V]) All() iter.Seq2[[]byte, V] {
+	return tree.tree.All()
+}
+
+// LongestSuffix returns the key which is the longest suffix of the given key at a label
+// boundary, and the value referred by this key.
+// Plus a boolean to indicate whether the key/value, is found.
+func (tree *SeparatorTree[V]) LongestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	tree.WalkSuffixMatches(key, LongestFirst, func(k []byte, v V) bool {
+		matchedKey, value, found = k, v, true
+		return true
+	})
+	return matchedKey, value, found
+}
+
+// ShortestSuffix returns the key which is the shortest suffix of the given key at a label
+// boundary, and the value referred by this key.
+// Plus a boolean to indicate whether the key/value, is found.
+func (tree *SeparatorTree[V]) ShortestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	tree.WalkSuffixMatches(key, ShortestFirst, func(k []byte, v V) bool {
+		matchedKey, value, found = k, v, true
+		return true
+	})
+	return matchedKey, value, found
+}
+
+// WalkSuffixMatches calls function with every key which is a suffix of the given key at a
+// label boundary, and the value referred by this key.
+// Once the function returns true, it will stop walking.
+func (tree *SeparatorTree[V]) WalkSuffixMatches(key []byte, order MatchOrder,
+	f func(matchedKey []byte, value V) bool) {
+
+	tree.tree.WalkSuffixMatches(key, order, func(matchedKey []byte, value V) bool {
+		if !tree.atBoundary(key, matchedKey) {
+			return false
+		}
+		return f(matchedKey, value)
+	})
+}
+
+// AllSuffixMatches returns an iterator over every key which is a suffix of the given key
+// at a label boundary, and the value referred by this key.
+func (tree *SeparatorTree[V]) AllSuffixMatches(key []byte, order MatchOrder) iter.Seq2[[]byte, V] {
+	return func(yield func(matchedKey []byte, value V) bool) {
+		tree.WalkSuffixMatches(key, order, func(matchedKey []byte, value V) bool {
+			return !yield(matchedKey, value)
+		})
+	}
+}
+
+// WalkSuffix travels through keys which have given suffix at a label boundary,
+// calls function with key and value.
+// Once the function returns true, it will stop walking.
+// The travelling order is the same as Tree.WalkSuffix. Like Tree.WalkSuffix, an empty
+// suffix matches every key.
+func (tree *SeparatorTree[V]) WalkSuffix(suffix []byte, f func(key []byte, value V) bool) {
+	if len(suffix) == 0 {
+		tree.tree.WalkSuffix(suffix, f)
+		return
+	}
+	tree.tree.WalkSuffix(suffix, func(key []byte, value V) bool {
+		if !tree.atBoundary(key, suffix) {
+			return false
+		}
+		return f(key, value)
+	})
+}
+
+// WithSuffix returns an iterator over keys which have given suffix at a label boundary
+// and their values. The travelling order is the same as WalkSuffix.
+func (tree *SeparatorTree[V]) WithSuffix(suffix []byte) iter.Seq2[[]byte, V] {
+	return func(yield func(key []byte, value V) bool) {
+		tree.WalkSuffix(suffix, func(key []byte, value V) bool {
+			return !yield(key, value)
+		})
+	}
+}
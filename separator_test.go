@@ -0,0 +1,154 @@
+package suffix
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getDomainFixtures() *SeparatorTree[string] {
+	tree := NewDomainTree[string]()
+	for _, s := range []string{
+		"com", "example.com", "ample.com", "www.example.com", ".cdn.net", "net",
+	} {
+		tree.Insert([]byte(s), s)
+	}
+	return tree
+}
+
+func TestSeparatorTree_Get(t *testing.T) {
+	tree := getDomainFixtures()
+	value, found := tree.Get([]byte("example.com"))
+	assert.True(t, found)
+	assert.Equal(t, "example.com", value)
+	_, found = tree.Get([]byte("le.com"))
+	assert.False(t, found)
+	assert.Equal(t, 6, tree.Len())
+
+	_, found = tree.Remove([]byte("ample.com"))
+	assert.True(t, found)
+	assert.Equal(t, 5, tree.Len())
+}
+
+func TestSeparatorTree_LongestSuffix(t *testing.T) {
+	tree := getDomainFixtures()
+	cases := map[string]string{
+		"example.com":       "example.com",
+		"a.example.com":     "example.com",
+		"notexample.com":    "com",
+		"sample.com":        "com",
+		"a.ample.com":       "ample.com",
+		"a.www.example.com": "www.example.com",
+		"www.cdn.net":       ".cdn.net",
+		"wwwcdn.net":        "net",
+		".cdn.net":          ".cdn.net",
+		"dotcom":            "",
+		"org":               "",
+	}
+	for key, expected := range cases {
+		matchedKey, value, found := tree.LongestSuffix([]byte(key))
+		assert.Equal(t, expected != "", found, "key %s", key)
+		assert.Equal(t, expected, string(matchedKey), "key %s", key)
+		assert.Equal(t, expected, value, "key %s", key)
+	}
+
+	matchedKey, _, found := tree.ShortestSuffix([]byte("a.www.example.com"))
+	assert.True(t, found)
+	assert.Equal(t, "com", string(matchedKey))
+	_, _, found = tree.ShortestSuffix([]byte("dotcom"))
+	assert.False(t, found)
+}
+
+func TestSeparatorTree_SuffixMatches(t *testing.T) {
+	tree := getDomainFixtures()
+	matches := []string{}
+	for key := range tree.AllSuffixMatches([]byte("www.notexample.com"), LongestFirst) {
+		matches = append(matches, string(key))
+	}
+	assert.Equal(t, []string{"com"}, matches)
+
+	matches = []string{}
+	for key := range tree.AllSuffixMatches([]byte("a.www.example.com"), ShortestFirst) {
+		matches = append(matches, string(key))
+	}
+	assert.Equal(t, []string{"com", "example.com", "www.example.com"}, matches)
+}
+
+func TestSeparatorTree_WalkSuffix(t *testing.T) {
+	tree := getDomainFixtures()
+	keys := []string{}
+	for key := range tree.WithSuffix([]byte("example.com")) {
+		keys = append(keys, string(key))
+	}
+	assert.Equal(t, []string{"example.com", "www.example.com"}, keys)
+
+	keys = []string{}
+	tree.WalkSuffix([]byte("com"), func(key []byte, _ string) bool {
+		keys = append(keys, string(key))
+		return false
+	})
+	sort.Strings(keys)
+	assert.Equal(t, []string{"ample.com", "com", "example.com", "www.example.com"}, keys)
+
+	keys = []string{}
+	tree.WalkSuffix([]byte("ple.com"), func(key []byte, _ string) bool {
+		keys = append(keys, string(key))
+		return false
+	})
+	assert.Empty(t, keys)
+
+	keys = []string{}
+	tree.WalkSuffix([]byte(".com"), func(key []byte, _ string) bool {
+		keys = append(keys, string(key))
+		return false
+	})
+	sort.Strings(keys)
+	assert.Equal(t, []string{"ample.com", "example.com", "www.example.com"}, keys)
+}
+
+func TestSeparatorTree_EmptyKey(t *testing.T) {
+	tree := NewDomainTree[string]()
+	for _, s := range []string{"example.com", "com", ""} {
+		tree.Insert([]byte(s), s)
+	}
+
+	keys := []string{}
+	tree.WalkSuffix(nil, func(key []byte, _ string) bool {
+		keys = append(keys, string(key))
+		return false
+	})
+	sort.Strings(keys)
+	assert.Equal(t, []string{"", "com", "example.com"}, keys)
+	keys = []string{}
+	for key := range tree.WithSuffix([]byte{}) {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"", "com", "example.com"}, keys)
+
+	// The empty key only matches itself
+	matchedKey, _, found := tree.ShortestSuffix([]byte("a.example.com"))
+	assert.True(t, found)
+	assert.Equal(t, "com", string(matchedKey))
+	_, _, found = tree.LongestSuffix([]byte("org"))
+	assert.False(t, found)
+	_, _, found = tree.ShortestSuffix([]byte("com."))
+	assert.False(t, found)
+	matchedKey, _, found = tree.LongestSuffix([]byte{})
+	assert.True(t, found)
+	assert.Equal(t, "", string(matchedKey))
+}
+
+func TestSeparatorTree_Path(t *testing.T) {
+	tree := NewSeparatorTree[int]('/')
+	assert.Equal(t, byte('/'), tree.Separator())
+	tree.Insert([]byte("static"), 1)
+	tree.Insert([]byte("/img/static"), 2)
+	matchedKey, value, found := tree.LongestSuffix([]byte("/a/img/static"))
+	assert.True(t, found)
+	assert.Equal(t, "/img/static", string(matchedKey))
+	assert.Equal(t, 2, value)
+	_, _, found = tree.LongestSuffix([]byte("/a/nonstatic"))
+	assert.False(t, found)
+}
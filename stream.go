@@ -0,0 +1,126 @@
+package suffix
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrStopped is returned by Matcher after the function asks to stop matching.
+var ErrStopped = errors.New("suffix: matcher is stopped")
+
+// MatchEvent is reported by Matcher when a key is the longest suffix of the stream seen so far.
+type MatchEvent[V any] struct {
+	// The number of bytes consumed when the key is matched, so the key ends at Offset.
+	Offset int64
+	// Key refers to the internal window of Matcher, it is only valid during the function call.
+	Key   []byte
+	Value V
+}
+
+// Matcher consumes a stream, and reports the longest key which is a suffix of the stream
+// after each byte. Only a window of the last bytes, as long as the longest key in the tree,
+// is kept, so the whole stream is never buffered.
+// The tree should not be modified during a Write or ReadFrom call. If a longer key is
+// inserted between the calls, the bytes out of the previous window are lost, so the key
+// is only matched when it is consumed after the insertion.
+// A Matcher is not safe for concurrent use.
+type Matcher[V any] struct {
+	tree *Tree[V]
+	f    func(event MatchEvent[V]) bool
+	// The tail of the stream. It is twice as large as the window, so the window is moved
+	// to the beginning only after consuming as many bytes as the window.
+	buf     []byte
+	offset  int64
+	stopped bool
+}
+
+// NewMatcher creates a Matcher which calls function with each match. Once the function
+// returns true, the Matcher will stop matching. The empty key is ignored.
+func (tree *Tree[V]) NewMatcher(f func(event MatchEvent[V]) bool) *Matcher[V] {
+	return &Matcher[V]{
+		tree: tree,
+		f:    f,
+	}
+}
+
+// Write implements io.Writer. It always consumes the whole p, unless the Matcher is stopped,
+// which returns ErrStopped and the number of bytes consumed before stopping.
+func (m *Matcher[V]) Write(p []byte) (n int, err error) {
+	if m.stopped {
+		return 0, ErrStopped
+	}
+	windowSize := m.tree.maxKeyLen
+	if windowSize == 0 || len(m.tree.root.edges) == 0 {
+		m.offset += int64(len(p))
+		m.buf = m.buf[:0]
+		return len(p), nil
+	}
+	if cap(m.buf) < 2*windowSize {
+		// The tree has a longer key now
+		buf := make([]byte, 0, 2*windowSize)
+		m.buf = append(buf, m.buf...)
+	}
+
+	for i, b := range p {
+		if len(m.buf) == cap(m.buf) {
+			// Keep the bytes which could be the beginning of a key
+			keep := windowSize - 1
+			copy(m.buf, m.buf[len(m.buf)-keep:])
+			m.buf = m.buf[:keep]
+		}
+		m.buf = append(m.buf, b)
+		m.offset++
+
+		window := m.buf
+		if len(window) > windowSize {
+			window = window[len(window)-windowSize:]
+		}
+		key, value, found := nodeLongestSuffix(m.tree.root, window)
+		if !found || len(key) == 0 {
+			continue
+		}
+		if m.f(MatchEvent[V]{
+			Offset: m.offset,
+			Key:    window[len(window)-len(key):],
+			Value:  value,
+		}) {
+			m.stopped = true
+			return i + 1, ErrStopped
+		}
+	}
+	return len(p), nil
+}
+
+// ReadFrom implements io.ReaderFrom. It consumes r until EOF or the Matcher is stopped.
+// Return the number of bytes consumed, and the error during reading, except io.EOF.
+func (m *Matcher[V]) ReadFrom(r io.Reader) (n int64, err error) {
+	chunk := make([]byte, 32*1024)
+	for {
+		nr, rerr := r.Read(chunk)
+		if nr > 0 {
+			nw, werr := m.Write(chunk[:nr])
+			n += int64(nw)
+			if werr != nil {
+				return n, werr
+			}
+		}
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
+
+// Offset returns the number of bytes consumed.
+func (m *Matcher[V]) Offset() int64 {
+	return m.offset
+}
+
+// Reset discards the consumed bytes, so the Matcher could be reused for another stream.
+func (m *Matcher[V]) Reset() {
+	m.buf = m.buf[:0]
+	m.offset = 0
+	m.stopped = false
+}
@@ -0,0 +1,144 @@
+package suffix
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type streamMatch struct {
+	offset int64
+	key    string
+}
+
+func collectStreamMatches(t *testing.T, tree *Tree[string]) (*Matcher[string], *[]streamMatch) {
+	matches := []streamMatch{}
+	m := tree.NewMatcher(func(event MatchEvent[string]) bool {
+		if string(event.Key) != event.Value {
+			t.Errorf("key %q mismatches value %q", event.Key, event.Value)
+		}
+		matches = append(matches, streamMatch{event.Offset, string(event.Key)})
+		return false
+	})
+	return m, &matches
+}
+
+func expectedStreamMatches(tree *Tree[string], stream []byte) []streamMatch {
+	matches := []streamMatch{}
+	for i := 1; i <= len(stream); i++ {
+		key, _, found := tree.LongestSuffix(stream[:i])
+		if found && len(key) > 0 {
+			matches = append(matches, streamMatch{int64(i), string(key)})
+		}
+	}
+	return matches
+}
+
+func TestMatcher(t *testing.T) {
+	tree := New[string]()
+	for _, word := range []string{"", "GET", "POST", "/index.html", "200"} {
+		tree.InsertString(word, word)
+	}
+	m, matches := collectStreamMatches(t, tree)
+	n, err := m.Write([]byte("GET /ind"))
+	assert.Nil(t, err)
+	assert.Equal(t, 8, n)
+	m.Write([]byte("ex.html 200\nPOST"))
+	assert.Equal(t, []streamMatch{{3, "GET"}, {15, "/index.html"}, {19, "200"}, {24, "POST"}},
+		*matches)
+	assert.Equal(t, int64(24), m.Offset())
+
+	m.Reset()
+	*matches = (*matches)[:0]
+	nr, err := m.ReadFrom(iotest.OneByteReader(strings.NewReader("x200")))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), nr)
+	assert.Equal(t, []streamMatch{{4, "200"}}, *matches)
+
+	// Empty tree
+	m, matches = collectStreamMatches(t, New[string]())
+	m.Write([]byte("GET"))
+	assert.Equal(t, 0, len(*matches))
+	assert.Equal(t, int64(3), m.Offset())
+}
+
+func TestMatcher_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	for turn := 0; turn < 50; turn++ {
+		tree := New[string]()
+		for i := 0; i < 8; i++ {
+			key := randomKey(r, letters, 1, 8)
+			tree.Insert(key, string(key))
+		}
+		stream := randomKey(r, letters, 1024, 1024)
+		m, matches := collectStreamMatches(t, tree)
+		for rest := stream; len(rest) > 0; {
+			size := r.Intn(64)
+			if size > len(rest) {
+				size = len(rest)
+			}
+			m.Write(rest[:size])
+			rest = rest[size:]
+		}
+		assert.Equal(t, expectedStreamMatches(tree, stream), *matches)
+	}
+}
+
+func TestMatcher_TreeGrows(t *testing.T) {
+	tree := New[string]()
+	tree.InsertString("ab", "ab")
+	m, matches := collectStreamMatches(t, tree)
+	m.Write([]byte("xxab"))
+	tree.InsertString("abcdefab", "abcdefab")
+	m.Write([]byte("zabcdefab"))
+	assert.Equal(t, []streamMatch{{4, "ab"}, {7, "ab"}, {13, "abcdefab"}}, *matches)
+}
+
+func TestMatcher_Stop(t *testing.T) {
+	tree := New[string]()
+	tree.InsertString("ab", "ab")
+	count := 0
+	m := tree.NewMatcher(func(event MatchEvent[string]) bool {
+		count++
+		return count == 2
+	})
+	n, err := m.Write([]byte("abxabxab"))
+	assert.Equal(t, 5, n)
+	assert.True(t, errors.Is(err, ErrStopped))
+	_, err = m.Write([]byte("ab"))
+	assert.True(t, errors.Is(err, ErrStopped))
+	nr, err := m.ReadFrom(bytes.NewReader([]byte("ab")))
+	assert.Equal(t, int64(0), nr)
+	assert.True(t, errors.Is(err, ErrStopped))
+	assert.Equal(t, 2, count)
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	tree := New[string]()
+	for i := 0; i < 1000; i++ {
+		key := make([]byte, 8)
+		for j := range key {
+			key[j] = byte('a' + rand.Intn(26))
+		}
+		tree.Insert(key, string(key))
+	}
+	stream := make([]byte, 64*1024)
+	for i := range stream {
+		stream[i] = byte('a' + rand.Intn(26))
+	}
+	m := tree.NewMatcher(func(event MatchEvent[string]) bool {
+		return false
+	})
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Write(stream)
+	}
+}
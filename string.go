@@ -0,0 +1,41 @@
+package suffix
+
+// InsertString is like Insert, but takes a string key. The key is always copied into the
+// tree, as a string can't be stored as a byte slice.
+func (tree *Tree[V]) InsertString(key string, value V) (oldValue V, ok bool) {
+	owned := copyKey(&tree.arena, key)
+	oldValue, replaced := tree.insert(owned, value)
+	if replaced {
+		tree.arena.undo(owned)
+	}
+	return oldValue, true
+}
+
+// GetString is like Get, but takes a string key. It doesn't convert the key into a
+// byte slice, so there is no allocation.
+func (tree *Tree[V]) GetString(key string) (value V, found bool) {
+	if len(tree.root.edges) == 0 {
+		return value, false
+	}
+	return nodeGet(tree.root, key)
+}
+
+// LongestSuffixString is like LongestSuffix, but takes and returns string keys.
+func (tree *Tree[V]) LongestSuffixString(key string) (matchedKey string, value V, found bool) {
+	if len(tree.root.edges) == 0 {
+		return "", value, false
+	}
+	matched, value, found := nodeLongestSuffix(tree.root, key)
+	if found {
+		matchedKey = string(matched)
+	}
+	return matchedKey, value, found
+}
+
+// WalkSuffixString is like WalkSuffix, but takes a string suffix and calls function with
+// string keys.
+func (tree *Tree[V]) WalkSuffixString(suffix string, f func(key string, value V) bool) {
+	walkSuffix(tree.root, suffix, func(key []byte, value V) bool {
+		return f(string(key), value)
+	})
+}
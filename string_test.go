@@ -0,0 +1,95 @@
+package suffix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringAPI(t *testing.T) {
+	tree := New[int]()
+	_, found := tree.GetString("able")
+	assert.False(t, found)
+	_, _, found = tree.LongestSuffixString("table")
+	assert.False(t, found)
+
+	words := []string{"", "able", "table", "stable", "capable", "bale"}
+	for i, word := range words {
+		_, ok := tree.InsertString(word, i)
+		assert.True(t, ok)
+	}
+	oldValue, _ := tree.InsertString("able", 10)
+	assert.Equal(t, 1, oldValue)
+	assert.Equal(t, len(words), tree.Len())
+
+	for i, word := range words[2:] {
+		value, found := tree.GetString(word)
+		assert.True(t, found, word)
+		assert.Equal(t, i+2, value)
+		// Both forms of API see the same content
+		value, _ = tree.Get([]byte(word))
+		assert.Equal(t, i+2, value)
+	}
+	_, found = tree.GetString("ble")
+	assert.False(t, found)
+
+	matchedKey, value, found := tree.LongestSuffixString("unstable")
+	assert.True(t, found)
+	assert.Equal(t, "stable", matchedKey)
+	assert.Equal(t, 3, value)
+	matchedKey, value, found = tree.LongestSuffixString("enable")
+	assert.True(t, found)
+	assert.Equal(t, "able", matchedKey)
+	assert.Equal(t, 10, value)
+	matchedKey, _, found = tree.LongestSuffixString("xyz")
+	assert.True(t, found)
+	assert.Equal(t, "", matchedKey)
+
+	walked := []string{}
+	tree.WalkSuffixString("able", func(key string, value int) bool {
+		walked = append(walked, key)
+		return false
+	})
+	assert.Equal(t, []string{"able", "table", "stable", "capable"}, walked)
+	walked = []string{}
+	tree.WalkSuffixString("", func(key string, value int) bool {
+		walked = append(walked, key)
+		return false
+	})
+	assert.Equal(t, len(words), len(walked))
+}
+
+func TestStringLookupAllocs(t *testing.T) {
+	tree := New[int]()
+	for i, word := range []string{"able", "table", "stable", "capable", "bale"} {
+		tree.InsertString(word, i)
+	}
+	key := "unstable"
+	allocs := testing.AllocsPerRun(100, func() {
+		tree.GetString(key)
+		tree.GetString("capable")
+	})
+	assert.Equal(t, 0.0, allocs)
+	allocs = testing.AllocsPerRun(100, func() {
+		tree.LongestSuffixString("unknown")
+	})
+	assert.Equal(t, 0.0, allocs)
+	allocs = testing.AllocsPerRun(100, func() {
+		tree.WalkSuffixString("xyz", func(key string, value int) bool {
+			return false
+		})
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func BenchmarkGetString(b *testing.B) {
+	tree := New[int]()
+	for i, word := range []string{"able", "table", "stable", "capable", "bale"} {
+		tree.InsertString(word, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.GetString("capable")
+	}
+}
@@ -0,0 +1,253 @@
+package suffix
+
+import (
+	"sort"
+)
+
+// The root of SubstringIndex is always the first node
+const substringRoot = 0
+
+// A node of the generalized suffix tree. The label of the edge leading to it is stored in
+// the node.
+type _SubstringNode struct {
+	// The label is text[start:end]. A leaf has end -1, as it ends at the terminator of its key.
+	start int32
+	end   int32
+	// Suffix link of internal node
+	link     int32
+	children map[int32]int32
+	// For leaf, the key which has this suffix, and the position in text where the suffix starts
+	key    int32
+	suffix int32
+}
+
+// Occurrence is a position where the pattern occurs in a key of SubstringIndex or SuffixArray.
+type Occurrence struct {
+	// The id of the key
+	Key    int
+	Offset int
+}
+
+// Sort the occurrences by the key id and then the offset.
+func sortOccurrences(occurrences []Occurrence) {
+	sort.Slice(occurrences, func(i, j int) bool {
+		if occurrences[i].Key != occurrences[j].Key {
+			return occurrences[i].Key < occurrences[j].Key
+		}
+		return occurrences[i].Offset < occurrences[j].Offset
+	})
+}
+
+// SubstringIndex is a generalized suffix tree of all the added keys, built with Ukkonen's
+// algorithm. Unlike Tree, which finds the keys ending with a pattern, it finds the keys
+// containing a pattern, in time proportional to the length of pattern plus the number of
+// occurrences.
+// Keys are added incrementally, but can't be removed.
+type SubstringIndex struct {
+	// All the keys, each one is followed by a unique terminator. A byte is stored as it is,
+	// and the terminator of the i-th key is -i-1, so it doesn't equal to any other symbol.
+	text      []int32
+	keyStarts []int32
+	// The end of the terminator of each key
+	keyEnds []int32
+	nodes   []_SubstringNode
+
+	// The active point of Ukkonen's algorithm
+	activeNode   int32
+	activeEdge   int32
+	activeLength int32
+	remainder    int32
+}
+
+// NewSubstringIndex creates an empty SubstringIndex.
+func NewSubstringIndex() *SubstringIndex {
+	idx := &SubstringIndex{}
+	idx.newNode(0, 0, -1, -1)
+	return idx
+}
+
+func (idx *SubstringIndex) newNode(start, end, key, suffix int32) int32 {
+	idx.nodes = append(idx.nodes, _SubstringNode{
+		start:  start,
+		end:    end,
+		link:   substringRoot,
+		key:    key,
+		suffix: suffix,
+	})
+	return int32(len(idx.nodes) - 1)
+}
+
+func (idx *SubstringIndex) isLeaf(node int32) bool {
+	return idx.nodes[node].end == -1
+}
+
+func (idx *SubstringIndex) edgeEnd(node int32) int32 {
+	if end := idx.nodes[node].end; end != -1 {
+		return end
+	}
+	// The leaf grows with the text until the terminator of its key is added
+	end := idx.keyEnds[idx.nodes[node].key]
+	if textLen := int32(len(idx.text)); end > textLen {
+		return textLen
+	}
+	return end
+}
+
+func (idx *SubstringIndex) edgeLength(node int32) int32 {
+	return idx.edgeEnd(node) - idx.nodes[node].start
+}
+
+func (idx *SubstringIndex) addChild(parent int32, symbol int32, child int32) {
+	if idx.nodes[parent].children == nil {
+		idx.nodes[parent].children = map[int32]int32{}
+	}
+	idx.nodes[parent].children[symbol] = child
+}
+
+// Add the symbol at position i of text to the tree.
+func (idx *SubstringIndex) extend(i int32) {
+	symbol := idx.text[i]
+	key := int32(len(idx.keyStarts) - 1)
+	// The internal node created in this phase which is waiting for its suffix link
+	waiting := int32(-1)
+	idx.remainder++
+	for idx.remainder > 0 {
+		if idx.activeLength == 0 {
+			idx.activeEdge = i
+		}
+		edgeSymbol := idx.text[idx.activeEdge]
+		next, ok := idx.nodes[idx.activeNode].children[edgeSymbol]
+		if !ok {
+			// The suffix ends at the active node, add a leaf for the new symbol
+			leaf := idx.newNode(i, -1, key, i-idx.remainder+1)
+			idx.addChild(idx.activeNode, edgeSymbol, leaf)
+			if waiting != -1 {
+				idx.nodes[waiting].link = idx.activeNode
+				waiting = -1
+			}
+		} else {
+			edgeLen := idx.edgeLength(next)
+			if idx.activeLength >= edgeLen {
+				// Walk down to the next node
+				idx.activeEdge += edgeLen
+				idx.activeLength -= edgeLen
+				idx.activeNode = next
+				continue
+			}
+			if idx.text[idx.nodes[next].start+idx.activeLength] == symbol {
+				// The suffix is already in the tree, so are the shorter ones
+				if waiting != -1 && idx.activeNode != substringRoot {
+					idx.nodes[waiting].link = idx.activeNode
+				}
+				idx.activeLength++
+				break
+			}
+			// Split the edge, and add a leaf for the new symbol under the split point
+			start := idx.nodes[next].start
+			split := idx.newNode(start, start+idx.activeLength, -1, -1)
+			idx.addChild(idx.activeNode, edgeSymbol, split)
+			leaf := idx.newNode(i, -1, key, i-idx.remainder+1)
+			idx.addChild(split, symbol, leaf)
+			idx.nodes[next].start += idx.activeLength
+			idx.addChild(split, idx.text[idx.nodes[next].start], next)
+			if waiting != -1 {
+				idx.nodes[waiting].link = split
+			}
+			waiting = split
+		}
+
+		idx.remainder--
+		if idx.activeNode == substringRoot && idx.activeLength > 0 {
+			idx.activeLength--
+			idx.activeEdge = i - idx.remainder + 1
+		} else if idx.activeNode != substringRoot {
+			idx.activeNode = idx.nodes[idx.activeNode].link
+		}
+	}
+}
+
+// Add a key to the index, and return its id. The ids are assigned from 0 in order.
+func (idx *SubstringIndex) Add(key []byte) (id int) {
+	id = len(idx.keyStarts)
+	start := int32(len(idx.text))
+	idx.keyStarts = append(idx.keyStarts, start)
+	idx.keyEnds = append(idx.keyEnds, start+int32(len(key))+1)
+	for _, b := range key {
+		idx.text = append(idx.text, int32(b))
+		idx.extend(int32(len(idx.text) - 1))
+	}
+	// All the suffixes become leaves after adding the unique terminator
+	idx.text = append(idx.text, int32(-id-1))
+	idx.extend(int32(len(idx.text) - 1))
+	return id
+}
+
+// Len returns the number of keys.
+func (idx *SubstringIndex) Len() int {
+	return len(idx.keyStarts)
+}
+
+// Key returns a copy of the key with given id.
+func (idx *SubstringIndex) Key(id int) []byte {
+	symbols := idx.text[idx.keyStarts[id] : idx.keyEnds[id]-1]
+	key := make([]byte, len(symbols))
+	for i, symbol := range symbols {
+		key[i] = byte(symbol)
+	}
+	return key
+}
+
+// Return the node under which all the suffixes start with pattern, or -1 if not found.
+func (idx *SubstringIndex) locate(pattern []byte) int32 {
+	node := int32(substringRoot)
+	for i := 0; i < len(pattern); {
+		next, ok := idx.nodes[node].children[int32(pattern[i])]
+		if !ok {
+			return -1
+		}
+		start := idx.nodes[next].start
+		end := idx.edgeEnd(next)
+		for j := start; j < end && i < len(pattern); j++ {
+			if idx.text[j] != int32(pattern[i]) {
+				return -1
+			}
+			i++
+		}
+		node = next
+	}
+	return node
+}
+
+// Contains returns whether any key contains the pattern.
+func (idx *SubstringIndex) Contains(pattern []byte) bool {
+	return len(idx.keyStarts) > 0 && idx.locate(pattern) != -1
+}
+
+// Occurrences returns all the positions where the pattern occurs in the keys, ordered by
+// the key id and then the offset. The empty pattern occurs at every offset of each key,
+// including the one after the last byte.
+func (idx *SubstringIndex) Occurrences(pattern []byte) []Occurrence {
+	occurrences := []Occurrence{}
+	node := idx.locate(pattern)
+	if node == -1 {
+		return occurrences
+	}
+	stack := []int32{node}
+	for len(stack) > 0 {
+		node = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if idx.isLeaf(node) {
+			leaf := &idx.nodes[node]
+			occurrences = append(occurrences, Occurrence{
+				Key:    int(leaf.key),
+				Offset: int(leaf.suffix - idx.keyStarts[leaf.key]),
+			})
+			continue
+		}
+		for _, child := range idx.nodes[node].children {
+			stack = append(stack, child)
+		}
+	}
+	sortOccurrences(occurrences)
+	return occurrences
+}
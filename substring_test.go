@@ -0,0 +1,86 @@
+package suffix
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bruteForceOccurrences(keys [][]byte, pattern []byte) []Occurrence {
+	occurrences := []Occurrence{}
+	for id, key := range keys {
+		for offset := 0; offset+len(pattern) <= len(key); offset++ {
+			if bytes.HasPrefix(key[offset:], pattern) {
+				occurrences = append(occurrences, Occurrence{Key: id, Offset: offset})
+			}
+		}
+	}
+	return occurrences
+}
+
+func TestSubstringIndex(t *testing.T) {
+	idx := NewSubstringIndex()
+	assert.False(t, idx.Contains([]byte("")))
+	assert.Equal(t, 0, len(idx.Occurrences([]byte("a"))))
+
+	assert.Equal(t, 0, idx.Add([]byte("banana")))
+	assert.Equal(t, 1, idx.Add([]byte("bandana")))
+	assert.Equal(t, 2, idx.Add([]byte("")))
+	assert.Equal(t, 3, idx.Len())
+	assert.Equal(t, "bandana", string(idx.Key(1)))
+
+	assert.True(t, idx.Contains([]byte("ana")))
+	assert.True(t, idx.Contains([]byte("dan")))
+	assert.True(t, idx.Contains([]byte("")))
+	assert.False(t, idx.Contains([]byte("nab")))
+	assert.False(t, idx.Contains([]byte("bananas")))
+	assert.Equal(t, []Occurrence{{0, 1}, {0, 3}, {1, 4}}, idx.Occurrences([]byte("ana")))
+	assert.Equal(t, []Occurrence{{0, 0}, {1, 0}}, idx.Occurrences([]byte("ban")))
+	assert.Equal(t, []Occurrence{}, idx.Occurrences([]byte("x")))
+	// Every offset, including the one after the last byte
+	assert.Equal(t, 7+8+1, len(idx.Occurrences(nil)))
+}
+
+func TestSubstringIndex_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	for turn := 0; turn < 50; turn++ {
+		idx := NewSubstringIndex()
+		keys := [][]byte{}
+		for i := 0; i < 8; i++ {
+			key := randomKey(r, letters, 0, 15)
+			keys = append(keys, key)
+			assert.Equal(t, i, idx.Add(key))
+			// Query after each addition
+			for j := 0; j < 16; j++ {
+				pattern := randomKey(r, letters, 0, 4)
+				expected := bruteForceOccurrences(keys, pattern)
+				assert.Equal(t, expected, idx.Occurrences(pattern), string(pattern))
+				assert.Equal(t, len(expected) > 0, idx.Contains(pattern), string(pattern))
+			}
+		}
+		for id, key := range keys {
+			assert.Equal(t, key, idx.Key(id))
+		}
+	}
+}
+
+func BenchmarkSubstringIndexAdd(b *testing.B) {
+	keys := make([][]byte, 1000)
+	for i := range keys {
+		keys[i] = make([]byte, 32)
+		for j := range keys[i] {
+			keys[i][j] = byte('a' + rand.Intn(4))
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := NewSubstringIndex()
+		for _, key := range keys {
+			idx.Add(key)
+		}
+	}
+}
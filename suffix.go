@@ -1,10 +1,19 @@
 package suffix
 
 import (
-	"bytes"
 	"sort"
 )
 
+// _Key is the type of key accepted by the lookup functions, so the string variants of API
+// could look up the tree without converting the key into a byte slice.
+type _Key interface {
+	~string | ~[]byte
+}
+
+func hasSuffix[K1, K2 _Key](s K1, suffix K2) bool {
+	return len(s) >= len(suffix) && string(s[len(s)-len(suffix):]) == string(suffix)
+}
+
 // Return
 // the first index of the mismatch byte (from right to left, starts from 1)
 // len(left)+1 if left byte sequence is shorter than right one
@@ -30,24 +39,86 @@ func suffixDiff(left, right []byte) int {
 	return -rightLen - 1
 }
 
-type _Edge struct {
+type _Edge[V any] struct {
 	label []byte
 	// Could be either Node or Leaf
 	point interface{}
 }
 
-type _Leaf struct {
+type _Leaf[V any] struct {
 	// For LongestSuffix and so on. We choice to use more memory(24 bytes per node)
 	// over appending keys each time.
 	originKey []byte
-	value     interface{}
+	value     V
+}
+
+type _Node[V any] struct {
+	// Sorted by the length of label, so the shorter key comes first in walking.
+	// The edge with empty label, if exists, is always the first one.
+	edges []*_Edge[V]
+	// Find the edge with non-empty label by the last byte of label
+	index _Index[V]
+	// The number of leaves under this node
+	leavesNum int
+}
+
+func newNode[V any](edges ...*_Edge[V]) *_Node[V] {
+	node := &_Node[V]{
+		edges: edges,
+		index: &_Index4[V]{},
+	}
+	for _, edge := range edges {
+		if len(edge.label) > 0 {
+			node.index = node.index.add(lastByte(edge.label), edge)
+		}
+		node.leavesNum += edge.leavesNum()
+	}
+	return node
+}
+
+// Return the number of leaves under the edge
+func (edge *_Edge[V]) leavesNum() int {
+	if node, ok := edge.point.(*_Node[V]); ok {
+		return node.leavesNum
+	}
+	return 1
+}
+
+// Return the leaf under the empty label, or nil if there is no empty label.
+func (node *_Node[V]) emptyLeaf() *_Leaf[V] {
+	if len(node.edges) > 0 && len(node.edges[0].label) == 0 {
+		leaf, _ := node.edges[0].point.(*_Leaf[V])
+		return leaf
+	}
+	return nil
+}
+
+// Return the edge which's non-empty label is a suffix of the given key, or nil if not found.
+// As the labels don't share common suffix, there is at most one such edge.
+func matchEdge[V any, K _Key](node *_Node[V], key K) *_Edge[V] {
+	if len(key) == 0 {
+		return nil
+	}
+	edge := node.index.find(key[len(key)-1])
+	if edge == nil || !hasSuffix(key, edge.label) {
+		return nil
+	}
+	return edge
 }
 
-type _Node struct {
-	edges []*_Edge
+// Return the index of given edge in edges.
+func (node *_Node[V]) position(edge *_Edge[V]) int {
+	edgeLabelLen := len(edge.label)
+	i := sort.Search(len(node.edges), func(j int) bool {
+		return edgeLabelLen <= len(node.edges[j].label)
+	})
+	for node.edges[i] != edge {
+		i++
+	}
+	return i
 }
 
-func (node *_Node) insertEdge(edge *_Edge) {
+func (node *_Node[V]) insertEdge(edge *_Edge[V]) {
 	newEdgeLabelLen := len(edge.label)
 	idx := sort.Search(len(node.edges), func(i int) bool {
 		return newEdgeLabelLen < len(node.edges[i].label)
@@ -55,16 +126,22 @@ func (node *_Node) insertEdge(edge *_Edge) {
 	node.edges = append(node.edges, nil)
 	copy(node.edges[idx+1:], node.edges[idx:])
 	node.edges[idx] = edge
+	if newEdgeLabelLen > 0 {
+		node.index = node.index.add(lastByte(edge.label), edge)
+	}
 }
 
-func (node *_Node) removeEdge(idx int) {
+func (node *_Node[V]) removeEdge(idx int) {
+	if label := node.edges[idx].label; len(label) > 0 {
+		node.index = node.index.remove(lastByte(label))
+	}
 	copy(node.edges[idx:], node.edges[idx+1:])
 	node.edges[len(node.edges)-1] = nil
 	node.edges = node.edges[:len(node.edges)-1]
 }
 
 // Reorder edge which is not shorter than before
-func (node *_Node) backwardEdge(idx int) {
+func (node *_Node[V]) backwardEdge(idx int) {
 	edge := node.edges[idx]
 	edgeLabelLen := len(edge.label)
 	edgesLen := len(node.edges)
@@ -83,7 +160,7 @@ func (node *_Node) backwardEdge(idx int) {
 }
 
 // Reorder edge which is shorter than before
-func (node *_Node) forwardEdge(idx int) {
+func (node *_Node[V]) forwardEdge(idx int) {
 	edge := node.edges[idx]
 	edgeLabelLen := len(edge.label)
 	i := sort.Search(idx, func(j int) bool {
@@ -93,72 +170,109 @@ func (node *_Node) forwardEdge(idx int) {
 	node.edges[i] = edge
 }
 
-func (node *_Node) insert(originKey []byte, key []byte, value interface{}) (
-	oldValue interface{}, ok bool) {
+// _UpdateFunc decides the new value of key with the old value and a boolean to indicate
+// whether the key exists. The key is removed or not inserted if keep is false.
+type _UpdateFunc[V any] func(oldValue V, exists bool) (newValue V, keep bool)
 
-	start := 0
-	if len(node.edges) > 0 && len(node.edges[0].label) == 0 {
+// Return the new value decided by f, or the given value if f is nil.
+func (f _UpdateFunc[V]) apply(value V, oldValue V, exists bool) (newValue V, keep bool) {
+	if f == nil {
+		return value, true
+	}
+	return f(oldValue, exists)
+}
+
+// Insert the key with given value, or the value decided by f if f is not nil.
+// Return the old value, a boolean to indicate whether the key existed, the change of
+// the number of leaves, and a boolean to indicate whether an edge of this node is removed.
+func (node *_Node[V]) insert(originKey []byte, key []byte, value V, f _UpdateFunc[V]) (
+	oldValue V, existed bool, leavesDiff int, childRemoved bool) {
+
+	if len(key) == 0 {
 		// handle empty label as a special case, so the rest of labels don't share
 		// common suffix
-		if len(key) == 0 {
-			leaf, _ := node.edges[0].point.(*_Leaf)
+		if leaf := node.emptyLeaf(); leaf != nil {
 			oldValue = leaf.value
-			leaf.value = value
-			return oldValue, true
+			newValue, keep := f.apply(value, oldValue, true)
+			if !keep {
+				node.removeEdge(0)
+				node.leavesNum--
+				return oldValue, true, -1, true
+			}
+			leaf.value = newValue
+			return oldValue, true, 0, false
 		}
-		start++
-	}
-	for i := start; i < len(node.edges); i++ {
-		edge := node.edges[i]
+	} else if edge := node.index.find(key[len(key)-1]); edge != nil {
+		// Only the edge which has the same last byte could share common suffix with the key
 		gap := suffixDiff(key, edge.label)
 		if gap == 0 {
 			// CASE 1: key == label
 			switch point := edge.point.(type) {
-			case *_Leaf:
+			case *_Leaf[V]:
 				// Leaf hitted, replace old value
 				oldValue = point.value
-				point.value = value
-				return oldValue, true
-			case *_Node:
+				newValue, keep := f.apply(value, oldValue, true)
+				if !keep {
+					node.removeEdge(node.position(edge))
+					node.leavesNum--
+					return oldValue, true, -1, true
+				}
+				point.value = newValue
+				return oldValue, true, 0, false
+			case *_Node[V]:
 				// Node hitted, insert a leaf under this Node
-				return point.insert(originKey, []byte{}, value)
+				oldValue, existed, leavesDiff, childRemoved = point.insert(
+					originKey, []byte{}, value, f)
+				node.leavesNum += leavesDiff
+				if childRemoved {
+					node.mergeChildNode(node.position(edge), point)
+				}
+				return oldValue, existed, leavesDiff, false
 			}
 		} else if gap < 0 {
 			// CASE 2: key > label
 			gap = -gap
 			label := key[:len(key)-gap+1]
 			switch point := edge.point.(type) {
-			case *_Leaf:
+			case *_Leaf[V]:
+				newValue, keep := f.apply(value, oldValue, false)
+				if !keep {
+					return oldValue, false, 0, false
+				}
 				// Before: Node - "label" -> Leaf(Value1)
 				// After: Node - "label" - Node - "" -> Leaf(Value1)
 				//							|- "s" -> Leaf(Value2)
 				// Create new Node, move old Leaf under new Node, and then
 				//	insert a new Leaf
-				newNode := &_Node{
-					edges: []*_Edge{
-						{
-							label: []byte{},
-							point: point,
-						},
-						{
-							label: label,
-							point: &_Leaf{
-								originKey: originKey,
-								value:     value,
-							},
+				edge.point = newNode(
+					&_Edge[V]{
+						label: []byte{},
+						point: point,
+					},
+					&_Edge[V]{
+						label: label,
+						point: &_Leaf[V]{
+							originKey: originKey,
+							value:     newValue,
 						},
 					},
-				}
-				edge.point = newNode
-				return nil, true
-			case *_Node:
+				)
+				node.leavesNum++
+				return oldValue, false, 1, false
+			case *_Node[V]:
 				// Before: Node - "label" -> Node - "" -> Leaf(Value1)
 				// After: Node - "label" - Node - "" -> Leaf(Value1)
 				//							|- "s" -> Leaf(Value2)
 				// Insert a new Leaf with extra data as label
-				return point.insert(originKey, label, value)
+				oldValue, existed, leavesDiff, childRemoved = point.insert(
+					originKey, label, value, f)
+				node.leavesNum += leavesDiff
+				if childRemoved {
+					node.mergeChildNode(node.position(edge), point)
+				}
+				return oldValue, existed, leavesDiff, false
 			}
-		} else if gap > 1 {
+		} else {
 			// CASE 3: mismatch(key, label) after first letter or key < label
 			// Before: Node - "labels" -> Node/Leaf(Value1)
 			// After: Node - "label" - Node - "s" -> Node/Leaf(Value1)
@@ -166,369 +280,624 @@ func (node *_Node) insert(originKey []byte, key []byte, value interface{}) (
 			// Before: Node - "label" -> Node/Leaf(Value1)
 			// After: Node - "lab" - Node - "el" -> Node/Leaf(Value1)
 			//							|- "or" -> Leaf(Value2)
-			newEdge := &_Edge{
+			newValue, keep := f.apply(value, oldValue, false)
+			if !keep {
+				return oldValue, false, 0, false
+			}
+			newEdge := &_Edge[V]{
 				label: edge.label[:len(edge.label)-gap+1],
 				point: edge.point,
 			}
-			keyEdge := &_Edge{
+			keyEdge := &_Edge[V]{
 				label: key[:len(key)-gap+1],
-				point: &_Leaf{
+				point: &_Leaf[V]{
 					originKey: originKey,
-					value:     value,
+					value:     newValue,
 				},
 			}
-			newNode := &_Node{
-				edges: make([]*_Edge, 2),
-			}
 			if len(newEdge.label) < len(keyEdge.label) {
-				newNode.edges[0], newNode.edges[1] = newEdge, keyEdge
+				edge.point = newNode(newEdge, keyEdge)
 			} else {
-				newNode.edges[0], newNode.edges[1] = keyEdge, newEdge
+				edge.point = newNode(keyEdge, newEdge)
 			}
-			edge.point = newNode
+			i := node.position(edge)
+			// The last byte of label is unchanged, so is the index
 			edge.label = edge.label[len(edge.label)-gap+1:]
 			node.forwardEdge(i)
-			return nil, true
+			node.leavesNum++
+			return oldValue, false, 1, false
 		}
-		// CASE 4: totally mismatch
 	}
 
-	leaf := &_Leaf{
+	// CASE 4: totally mismatch
+	newValue, keep := f.apply(value, oldValue, false)
+	if !keep {
+		return oldValue, false, 0, false
+	}
+	leaf := &_Leaf[V]{
 		originKey: originKey,
-		value:     value,
+		value:     newValue,
 	}
-	edge := &_Edge{
+	edge := &_Edge[V]{
 		label: key,
 		point: leaf,
 	}
 	node.insertEdge(edge)
-	return nil, true
+	node.leavesNum++
+	return oldValue, false, 1, false
 }
 
-func (node *_Node) get(key []byte) (value interface{}, found bool) {
-	edges := node.edges
-	start := 0
-	if len(edges[0].label) == 0 {
+func nodeGet[V any, K _Key](node *_Node[V], key K) (value V, found bool) {
+	if len(key) == 0 {
 		// handle empty label as a special case, so the rest of labels don't share
 		// common suffix
-		if len(key) == 0 {
-			leaf, _ := edges[0].point.(*_Leaf)
+		if leaf := node.emptyLeaf(); leaf != nil {
 			return leaf.value, true
 		}
-		start++
+		return value, false
 	}
 
-	keyLen := len(key)
-	for i := start; i < len(edges); i++ {
-		edge := edges[i]
-		edgeLabelLen := len(edge.label)
-		if keyLen > edgeLabelLen {
-			if bytes.Equal(key[len(key)-len(edge.label):], edge.label) {
-				subKey := key[:len(key)-len(edge.label)]
-				switch point := edge.point.(type) {
-				case *_Leaf:
-					return nil, false
-				case *_Node:
-					return point.get(subKey)
-				}
-			}
-		} else if keyLen == edgeLabelLen {
-			if bytes.Equal(key, edge.label) {
-				switch point := edge.point.(type) {
-				case *_Leaf:
-					return point.value, true
-				case *_Node:
-					return point.get([]byte{})
-				}
+	edge := matchEdge(node, key)
+	if edge == nil {
+		return value, false
+	}
+	switch point := edge.point.(type) {
+	case *_Leaf[V]:
+		if len(key) == len(edge.label) {
+			return point.value, true
+		}
+	case *_Node[V]:
+		return nodeGet(point, key[:len(key)-len(edge.label)])
+	}
+	return value, false
+}
+
+func nodeLongestSuffix[V any, K _Key](node *_Node[V], key K) (
+	matchedKey []byte, value V, found bool) {
+
+	if edge := matchEdge(node, key); edge != nil {
+		switch point := edge.point.(type) {
+		case *_Leaf[V]:
+			return point.originKey, point.value, true
+		case *_Node[V]:
+			matchedKey, value, found := nodeLongestSuffix(point, key[:len(key)-len(edge.label)])
+			if found {
+				return matchedKey, value, found
 			}
-		} else {
-			break
 		}
 	}
 
-	return nil, false
+	// handle empty label as a special case, it is the shortest key under this node
+	if leaf := node.emptyLeaf(); leaf != nil {
+		return leaf.originKey, leaf.value, true
+	}
+	return nil, value, false
 }
 
-func (node *_Node) longestSuffix(key []byte) (matchedKey []byte, value interface{}, found bool) {
-	edges := node.edges
-	start := 0
-	if len(edges[0].label) == 0 {
-		// handle empty label as a special case, so the rest of labels don't share
-		// common suffix
-		if len(key) == 0 {
-			leaf, _ := edges[0].point.(*_Leaf)
-			return leaf.originKey, leaf.value, true
+func (node *_Node[V]) shortestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	// handle empty label as a special case, it is the shortest key under this node
+	if leaf := node.emptyLeaf(); leaf != nil {
+		return leaf.originKey, leaf.value, true
+	}
+
+	if edge := matchEdge(node, key); edge != nil {
+		switch point := edge.point.(type) {
+		case *_Leaf[V]:
+			return point.originKey, point.value, true
+		case *_Node[V]:
+			return point.shortestSuffix(key[:len(key)-len(edge.label)])
 		}
-		start++
+	}
+	return nil, value, false
+}
+
+// Call f with each leaf which's key is a suffix of the given key. It walks the same path
+// as longestSuffix. Return true once f asks to stop.
+func (node *_Node[V]) suffixMatches(key []byte, longestFirst bool, f func(leaf *_Leaf[V]) bool) bool {
+	// the leaf under empty label is always the shortest match in this node
+	emptyLeaf := node.emptyLeaf()
+	if emptyLeaf != nil && !longestFirst && f(emptyLeaf) {
+		return true
 	}
 
-	keyLen := len(key)
-	for i := start; i < len(edges); i++ {
-		edge := edges[i]
-		edgeLabelLen := len(edge.label)
-		if keyLen > edgeLabelLen {
-			if bytes.Equal(key[len(key)-len(edge.label):], edge.label) {
-				subKey := key[:len(key)-len(edge.label)]
-				switch point := edge.point.(type) {
-				case *_Leaf:
-					return point.originKey, point.value, true
-				case *_Node:
-					matchedKey, value, found := point.longestSuffix(subKey)
-					if found {
-						return matchedKey, value, found
-					}
-				}
+	if edge := matchEdge(node, key); edge != nil {
+		switch point := edge.point.(type) {
+		case *_Leaf[V]:
+			if f(point) {
+				return true
 			}
-		} else if keyLen == edgeLabelLen {
-			if bytes.Equal(key, edge.label) {
-				switch point := edge.point.(type) {
-				case *_Leaf:
-					return point.originKey, point.value, true
-				case *_Node:
-					matchedKey, value, found := point.longestSuffix([]byte{})
-					if found {
-						return matchedKey, value, found
-					}
-				}
+		case *_Node[V]:
+			if point.suffixMatches(key[:len(key)-len(edge.label)], longestFirst, f) {
+				return true
 			}
-		} else {
-			break
 		}
 	}
 
-	if start == 1 {
-		leaf, _ := edges[0].point.(*_Leaf)
-		return leaf.originKey, leaf.value, true
+	if emptyLeaf != nil && longestFirst {
+		return f(emptyLeaf)
 	}
-
-	return nil, nil, false
+	return false
 }
 
-func (node *_Node) mergeChildNode(idx int, child *_Node) {
+func (node *_Node[V]) mergeChildNode(idx int, child *_Node[V]) {
 	if len(child.edges) == 1 {
 		edge := node.edges[idx]
 		edge.point = child.edges[0].point
-		edge.label = append(child.edges[0].label, edge.label...)
+		// Allocate a new label instead of appending to the child's label, which may share
+		// the underlying array with the labels in other snapshots.
+		// The last byte of label is unchanged, so is the index.
+		label := make([]byte, 0, len(child.edges[0].label)+len(edge.label))
+		edge.label = append(append(label, child.edges[0].label...), edge.label...)
 		node.backwardEdge(idx)
+		// The leaves under the edge are unchanged, so is the leavesNum
 	}
 	// When child has only one edge, we will remove the child and merge its label,
 	// So there is no case that child has no edge.
 }
 
-func (node *_Node) remove(key []byte) (value interface{}, found bool, childRemoved bool) {
-	edges := node.edges
-	start := 0
-	if len(edges[0].label) == 0 {
+func (node *_Node[V]) remove(key []byte) (value V, found bool, childRemoved bool) {
+	if len(key) == 0 {
 		// handle empty label as a special case, so the rest of labels don't share
 		// common suffix
-		if len(key) == 0 {
-			leaf, _ := edges[0].point.(*_Leaf)
-			value = leaf.value
+		if leaf := node.emptyLeaf(); leaf != nil {
 			node.removeEdge(0)
-			return value, true, true
+			node.leavesNum--
+			return leaf.value, true, true
 		}
-		start++
+		return value, false, false
 	}
 
-	keyLen := len(key)
-	for i := start; i < len(edges); i++ {
-		edge := edges[i]
-		edgeLabelLen := len(edge.label)
-		if keyLen > edgeLabelLen {
-			if bytes.Equal(key[len(key)-len(edge.label):], edge.label) {
-				key := key[:len(key)-len(edge.label)]
-				switch point := edge.point.(type) {
-				case *_Node:
-					value, found, childRemoved = point.remove(key)
-					if childRemoved {
-						node.mergeChildNode(i, point)
-					}
-					return value, found, false
-				}
-			}
-		} else if keyLen == edgeLabelLen {
-			if bytes.Equal(key, edge.label) {
-				switch point := edge.point.(type) {
-				case *_Leaf:
-					value = point.value
-					node.removeEdge(i)
-					return value, true, true
-				case *_Node:
-					value, found, childRemoved = point.remove([]byte{})
-					if childRemoved {
-						node.mergeChildNode(i, point)
-					}
-					return value, found, false
-				}
-			}
-		} else {
-			break
+	edge := matchEdge(node, key)
+	if edge == nil {
+		return value, false, false
+	}
+	switch point := edge.point.(type) {
+	case *_Leaf[V]:
+		if len(key) == len(edge.label) {
+			node.removeEdge(node.position(edge))
+			node.leavesNum--
+			return point.value, true, true
+		}
+	case *_Node[V]:
+		value, found, childRemoved = point.remove(key[:len(key)-len(edge.label)])
+		if found {
+			node.leavesNum--
 		}
+		if childRemoved {
+			node.mergeChildNode(node.position(edge), point)
+		}
+		return value, found, false
 	}
+	return value, false, false
+}
 
-	return nil, false, false
+// Detach the edge which leads to all the keys with given suffix. Return the detached edge,
+// or nil if there is no such key, and a boolean to indicate whether an edge of this node
+// is removed.
+func (node *_Node[V]) removeSuffix(suffix []byte) (detached *_Edge[V], childRemoved bool) {
+	edge := node.index.find(suffix[len(suffix)-1])
+	if edge == nil {
+		return nil, false
+	}
+	suffixLen := len(suffix)
+	if suffixLen > len(edge.label) {
+		child, ok := edge.point.(*_Node[V])
+		if !ok || !hasSuffix(suffix, edge.label) {
+			return nil, false
+		}
+		detached, childRemoved = child.removeSuffix(suffix[:suffixLen-len(edge.label)])
+		if detached != nil {
+			node.leavesNum -= detached.leavesNum()
+		}
+		if childRemoved {
+			node.mergeChildNode(node.position(edge), child)
+		}
+		return detached, false
+	}
+	if !hasSuffix(edge.label, suffix) {
+		return nil, false
+	}
+	node.removeEdge(node.position(edge))
+	node.leavesNum -= edge.leavesNum()
+	return edge, true
 }
 
 // return either _Leaf or _Node as interface{}
-func (node *_Node) getPointHasSuffix(key []byte) (interface{}, []byte, bool) {
-	edges := node.edges
+func getPointHasSuffix[V any, K _Key](node *_Node[V], key K) (interface{}, []byte, bool) {
+	edge := node.index.find(key[len(key)-1])
+	if edge == nil {
+		return nil, nil, false
+	}
 	keyLen := len(key)
-	for i := len(edges) - 1; i >= 0; i-- {
-		edge := edges[i]
-		edgeLabelLen := len(edge.label)
-		if keyLen > edgeLabelLen {
-			if bytes.Equal(key[len(key)-len(edge.label):], edge.label) {
-				subKey := key[:len(key)-len(edge.label)]
-				switch point := edge.point.(type) {
-				case *_Leaf:
-					return nil, nil, false
-				case *_Node:
-					return point.getPointHasSuffix(subKey)
-				}
-			}
-		} else {
-			if bytes.HasSuffix(edge.label, key) {
-				return edge.point, edge.label[:len(edge.label)-len(key)], true
+	if keyLen > len(edge.label) {
+		if hasSuffix(key, edge.label) {
+			switch point := edge.point.(type) {
+			case *_Leaf[V]:
+				return nil, nil, false
+			case *_Node[V]:
+				return getPointHasSuffix(point, key[:keyLen-len(edge.label)])
 			}
 		}
+	} else if hasSuffix(edge.label, key) {
+		return edge.point, edge.label[:len(edge.label)-keyLen], true
 	}
 	return nil, nil, false
 }
 
-func (node *_Node) walk(suffix []byte, f func(key []byte, value interface{}) bool, stop *bool) {
+// The key of leaf is taken from its originKey. We don't build the key by appending labels,
+// as it may write to the memory shared with other readers.
+func (node *_Node[V]) walk(f func(key []byte, value V) bool, stop *bool) {
 	for _, edge := range node.edges {
 		if *stop {
 			return
 		}
 		switch point := edge.point.(type) {
-		case *_Leaf:
-			*stop = f(append(edge.label, suffix...), point.value)
-		case *_Node:
-			point.walk(append(edge.label, suffix...), f, stop)
+		case *_Leaf[V]:
+			*stop = f(point.originKey, point.value)
+		case *_Node[V]:
+			point.walk(f, stop)
 		}
 	}
 }
 
-func (node *_Node) walkNode(suffix [][]byte, f func(labels [][]byte, value interface{})) {
-	f(append([][]byte{nil}, suffix...), nil)
-	nodes := []*_Edge{}
-	leaves := []*_Edge{}
+func (node *_Node[V]) walkNode(suffix [][]byte, f func(labels [][]byte, value V)) {
+	var zero V
+	f(append([][]byte{nil}, suffix...), zero)
+	nodes := []*_Edge[V]{}
+	leaves := []*_Edge[V]{}
 	for _, edge := range node.edges {
 		switch edge.point.(type) {
-		case *_Leaf:
+		case *_Leaf[V]:
 			leaves = append(leaves, edge)
-		case *_Node:
+		case *_Node[V]:
 			nodes = append(nodes, edge)
 		}
 	}
 	for _, edge := range leaves {
-		leaf, _ := edge.point.(*_Leaf)
+		leaf, _ := edge.point.(*_Leaf[V])
 		f(append([][]byte{edge.label}, suffix...), leaf.value)
 	}
 	for _, edge := range nodes {
-		node, _ := edge.point.(*_Node)
+		node, _ := edge.point.(*_Node[V])
 		node.walkNode(append([][]byte{edge.label}, suffix...), f)
 	}
 }
 
-// Tree represents a suffix tree.
-type Tree struct {
-	root      *_Node
+// Tree represents a suffix tree which stores values of type V.
+//
+// By default, the tree owns its keys: Insert copies the given key, and the keys returned
+// by the tree are copies too, so the caller is free to reuse or modify them.
+// A tree created by NewNoCopy stores the given keys as they are and returns them directly,
+// which saves the copying.
+type Tree[V any] struct {
+	root      *_Node[V]
 	leavesNum int
+	// Not shorter than any key. It is not decreased when a key is removed, until the tree
+	// becomes empty.
+	maxKeyLen int
+	// Used by MarshalBinary and UnmarshalBinary
+	codec ValueCodec[V]
+	// Where the copied keys are allocated
+	arena _Arena
+	// Store the inserted keys without copying
+	borrowKeys bool
 }
 
-// NewTree create a suffix tree for future usage.
-func NewTree() *Tree {
-	return &Tree{
-		root: &_Node{
-			edges: []*_Edge{},
-		},
+// New creates a suffix tree whose values are typed as V.
+func New[V any]() *Tree[V] {
+	return &Tree[V]{
+		root:      newNode[V](),
 		leavesNum: 0,
 	}
 }
 
+// NewNoCopy creates a suffix tree which doesn't copy the keys. The caller must not modify
+// a key after inserting it, and the keys returned by the tree are the inserted ones.
+func NewNoCopy[V any]() *Tree[V] {
+	tree := New[V]()
+	tree.borrowKeys = true
+	return tree
+}
+
+// NewTree create a suffix tree for future usage.
+// The created tree stores untyped values, like the suffix tree before the
+// introduction of generics. New code should prefer New, which avoids boxing
+// values and the type assertions on the lookup results.
+func NewTree() *Tree[interface{}] {
+	return New[interface{}]()
+}
+
 // Insert suffix tree with given key and value. Return the previous value and a boolean to
 // indicate whether the insertion is successful.
-func (tree *Tree) Insert(key []byte, value interface{}) (oldValue interface{}, ok bool) {
+// The insertion only fails when the key is nil. Use Put to know whether the key is replaced.
+func (tree *Tree[V]) Insert(key []byte, value V) (oldValue V, ok bool) {
 	if key == nil {
-		return nil, false
+		return oldValue, false
+	}
+	oldValue, _ = tree.Put(key, value)
+	return oldValue, true
+}
+
+// Put inserts given key and value into the tree. Return the previous value, and a boolean
+// to indicate whether an existing key is replaced. Unlike the previous value, which may be
+// the zero value stored by the caller, the boolean tells insertion from replacement.
+// A nil key is not inserted, and is reported as not replaced.
+func (tree *Tree[V]) Put(key []byte, value V) (oldValue V, replaced bool) {
+	if key == nil {
+		return oldValue, false
+	}
+	if !tree.borrowKeys {
+		key = copyKey(&tree.arena, key)
 	}
-	oldValue, ok = tree.root.insert(key, key, value)
-	if ok && oldValue == nil {
-		tree.leavesNum++
+	oldValue, replaced = tree.insert(key, value)
+	if replaced {
+		// The existing key is kept
+		tree.arena.undo(key)
+	}
+	return oldValue, replaced
+}
+
+// Insert the key which is owned by tree.
+func (tree *Tree[V]) insert(key []byte, value V) (oldValue V, replaced bool) {
+	oldValue, replaced, leavesDiff, _ := tree.root.insert(key, key, value, nil)
+	tree.addLeaves(leavesDiff, len(key))
+	return oldValue, replaced
+}
+
+// Update the value of key with the value decided by f in a single pass, f is called with
+// the old value and a boolean to indicate whether the key exists. If f returns keep as
+// false, the key is removed, or not inserted if it doesn't exist.
+// Return the new value, and a boolean to indicate whether the key exists after updating.
+func (tree *Tree[V]) Update(key []byte, f func(oldValue V, exists bool) (newValue V, keep bool)) (
+	value V, exists bool) {
+
+	if key == nil {
+		return value, false
+	}
+	if !tree.borrowKeys {
+		key = copyKey(&tree.arena, key)
+	}
+	keep := false
+	_, existed, leavesDiff, _ := tree.root.insert(key, key, value,
+		func(oldValue V, exists bool) (V, bool) {
+			value, keep = f(oldValue, exists)
+			return value, keep
+		})
+	tree.addLeaves(leavesDiff, len(key))
+	if existed || !keep {
+		// The key is not inserted
+		tree.arena.undo(key)
+	}
+	if !keep {
+		var zero V
+		return zero, false
+	}
+	return value, true
+}
+
+// GetOrInsert returns the value of key if it exists. Otherwise, it inserts the key with given
+// value and returns the value. The loaded result is true if the value is found.
+func (tree *Tree[V]) GetOrInsert(key []byte, value V) (actual V, loaded bool) {
+	actual, _ = tree.Update(key, func(oldValue V, exists bool) (V, bool) {
+		loaded = exists
+		if exists {
+			return oldValue, true
+		}
+		return value, true
+	})
+	return actual, loaded
+}
+
+// InsertIfAbsent inserts the key with given value only if the key doesn't exist.
+// Return true if the key is inserted.
+func (tree *Tree[V]) InsertIfAbsent(key []byte, value V) (inserted bool) {
+	_, loaded := tree.GetOrInsert(key, value)
+	return key != nil && !loaded
+}
+
+// Change the number of leaves after inserting or removing key with given length.
+func (tree *Tree[V]) addLeaves(diff int, keyLen int) {
+	tree.leavesNum += diff
+	if tree.leavesNum == 0 {
+		tree.maxKeyLen = 0
+	} else if diff > 0 && keyLen > tree.maxKeyLen {
+		tree.maxKeyLen = keyLen
+	}
+}
+
+// Return the key which is safe to give to the caller.
+func (tree *Tree[V]) exportKey(key []byte) []byte {
+	if tree.borrowKeys {
+		return key
+	}
+	return append(make([]byte, 0, len(key)), key...)
+}
+
+// Wrap the walking function, so it is called with the exported keys.
+func (tree *Tree[V]) exportWalkFunc(f func(key []byte, value V) bool) func(key []byte, value V) bool {
+	if tree.borrowKeys {
+		return f
+	}
+	return func(key []byte, value V) bool {
+		return f(tree.exportKey(key), value)
 	}
-	return oldValue, ok
 }
 
 // Get returns the value of given key and a boolean to indicate
 // whether the value is found.
-func (tree *Tree) Get(key []byte) (value interface{}, found bool) {
+func (tree *Tree[V]) Get(key []byte) (value V, found bool) {
 	if key == nil || len(tree.root.edges) == 0 {
-		return nil, false
+		return value, false
 	}
-	return tree.root.get(key)
+	return nodeGet(tree.root, key)
 }
 
 // LongestSuffix is mostly like Get.
 // It returns the key which is the longest suffix of the given key,
 // and the value referred by this key.
 // Plus a boolean to indicate whether the key/value, is found.
-func (tree *Tree) LongestSuffix(key []byte) (matchedKey []byte, value interface{}, found bool) {
+func (tree *Tree[V]) LongestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
 	if key == nil || len(tree.root.edges) == 0 {
-		return nil, nil, false
+		return nil, value, false
 	}
-	return tree.root.longestSuffix(key)
+	matchedKey, value, found = nodeLongestSuffix(tree.root, key)
+	if found {
+		matchedKey = tree.exportKey(matchedKey)
+	}
+	return matchedKey, value, found
+}
+
+// ShortestSuffix is like LongestSuffix, but returns the key which is the shortest suffix
+// of the given key, and the value referred by this key.
+// Plus a boolean to indicate whether the key/value, is found.
+func (tree *Tree[V]) ShortestSuffix(key []byte) (matchedKey []byte, value V, found bool) {
+	if key == nil || len(tree.root.edges) == 0 {
+		return nil, value, false
+	}
+	matchedKey, value, found = tree.root.shortestSuffix(key)
+	if found {
+		matchedKey = tree.exportKey(matchedKey)
+	}
+	return matchedKey, value, found
+}
+
+// MatchOrder specifies the order of matches reported by WalkSuffixMatches and AllSuffixMatches.
+type MatchOrder int
+
+const (
+	// LongestFirst reports the longest matched key first.
+	LongestFirst MatchOrder = iota
+	// ShortestFirst reports the shortest matched key first.
+	ShortestFirst
+)
+
+// WalkSuffixMatches calls function with every key which is a suffix of the given key,
+// and the value referred by this key.
+// Once the function returns true, it will stop walking.
+// The first key reported in LongestFirst order is the one returned by LongestSuffix.
+func (tree *Tree[V]) WalkSuffixMatches(key []byte, order MatchOrder,
+	f func(matchedKey []byte, value V) bool) {
+
+	if key == nil || len(tree.root.edges) == 0 {
+		return
+	}
+	f = tree.exportWalkFunc(f)
+	tree.root.suffixMatches(key, order == LongestFirst, func(leaf *_Leaf[V]) bool {
+		return f(leaf.originKey, leaf.value)
+	})
 }
 
 // Remove returns the value of given key and a boolean to indicate
 // whethe the value is found. Then the value will be removed.
-func (tree *Tree) Remove(key []byte) (oldValue interface{}, found bool) {
+func (tree *Tree[V]) Remove(key []byte) (oldValue V, found bool) {
 	if key == nil || len(tree.root.edges) == 0 {
-		return nil, false
+		return oldValue, false
 	}
 	oldValue, found, _ = tree.root.remove(key)
 	if found {
-		tree.leavesNum--
+		tree.addLeaves(-1, 0)
 	}
 	return oldValue, found
 }
 
+// RemoveSuffix removes all the keys which have given suffix, and returns the number of
+// removed keys. The keys are removed at once, instead of one by one.
+func (tree *Tree[V]) RemoveSuffix(suffix []byte) (removed int) {
+	return tree.RemoveSuffixFunc(suffix, nil)
+}
+
+// RemoveSuffixFunc is like RemoveSuffix, but also calls function with each removed key and
+// value, if the function is not nil. The function is called after the keys are removed.
+func (tree *Tree[V]) RemoveSuffixFunc(suffix []byte, f func(key []byte, value V)) (removed int) {
+	var detached *_Edge[V]
+	if len(suffix) == 0 {
+		// Detach the whole tree
+		detached = &_Edge[V]{
+			point: tree.root,
+		}
+		tree.root = newNode[V]()
+	} else {
+		detached, _ = tree.root.removeSuffix(suffix)
+		if detached == nil {
+			return 0
+		}
+	}
+	removed = detached.leavesNum()
+	tree.addLeaves(-removed, 0)
+
+	if f != nil {
+		walkFunc := tree.exportWalkFunc(func(key []byte, value V) bool {
+			f(key, value)
+			return false
+		})
+		switch point := detached.point.(type) {
+		case *_Leaf[V]:
+			walkFunc(point.originKey, point.value)
+		case *_Node[V]:
+			stop := false
+			point.walk(walkFunc, &stop)
+		}
+	}
+	return removed
+}
+
 // Len returns the number of keys.
-func (tree *Tree) Len() int {
+func (tree *Tree[V]) Len() int {
 	return tree.leavesNum
 }
 
 // Walk through the tree, call function with key and value.
 // Once the function returns true, it will stop walking.
 // The travelling order is DFS, in the same suffix level the shortest key comes first.
-func (tree *Tree) Walk(f func(key []byte, value interface{}) bool) {
+func (tree *Tree[V]) Walk(f func(key []byte, value V) bool) {
 	stop := false
-	tree.root.walk([]byte{}, f, &stop)
+	tree.root.walk(tree.exportWalkFunc(f), &stop)
 }
 
 // WalkSuffix travels through nodes which have given suffix, calls function with key and value.
 // Once the function returns true, it will stop walking.
 // The travelling order is DFS, in the same suffix level the shortest key comes first.
-func (tree *Tree) WalkSuffix(suffix []byte, f func(key []byte, value interface{}) bool) {
-	if len(tree.root.edges) != 0 {
+func (tree *Tree[V]) WalkSuffix(suffix []byte, f func(key []byte, value V) bool) {
+	walkSuffix(tree.root, suffix, tree.exportWalkFunc(f))
+}
+
+func walkSuffix[V any, K _Key](root *_Node[V], suffix K, f func(key []byte, value V) bool) {
+	if len(root.edges) != 0 {
 		stop := false
 		if len(suffix) == 0 {
-			tree.root.walk([]byte{}, f, &stop)
+			root.walk(f, &stop)
 		} else {
-			startingPoint, extraLabel, found := tree.root.getPointHasSuffix(suffix)
+			startingPoint, _, found := getPointHasSuffix(root, suffix)
 			if found {
 				switch point := startingPoint.(type) {
-				case *_Leaf:
+				case *_Leaf[V]:
 					f(point.originKey, point.value)
-				case *_Node:
-					point.walk(append(extraLabel, suffix...), f, &stop)
+				case *_Node[V]:
+					point.walk(f, &stop)
 				}
 			}
 		}
 	}
 }
 
+// CountSuffix returns the number of keys which have given suffix. Unlike WalkSuffix,
+// it doesn't travel through the keys, so the cost only depends on the length of suffix.
+func (tree *Tree[V]) CountSuffix(suffix []byte) int {
+	if len(suffix) == 0 {
+		return tree.leavesNum
+	}
+	startingPoint, _, found := getPointHasSuffix(tree.root, suffix)
+	if !found {
+		return 0
+	}
+	switch point := startingPoint.(type) {
+	case *_Node[V]:
+		return point.leavesNum
+	}
+	return 1
+}
+
 // This API is for testing/debug
-func (tree *Tree) walkNode(f func(labels [][]byte, value interface{})) {
+func (tree *Tree[V]) walkNode(f func(labels [][]byte, value V)) {
 	tree.root.walkNode([][]byte{}, f)
 }
@@ -25,7 +25,7 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func getFixtures() ([]string, *Tree) {
+func getFixtures() ([]string, *Tree[interface{}]) {
 	tree := NewTree()
 	lists := []string{
 		"edible", "presentable", "abominable", "credible",
@@ -49,7 +49,90 @@ func TestInsertReturn(t *testing.T) {
 	assert.Equal(t, "sth", oldValue.(string))
 }
 
-func assertGet(t *testing.T, tree *Tree, expectedValue string, expectedFound bool) {
+func TestPut(t *testing.T) {
+	tree := New[int]()
+	oldValue, replaced := tree.Put([]byte("able"), 1)
+	assert.False(t, replaced)
+	assert.Equal(t, 0, oldValue)
+	oldValue, replaced = tree.Put([]byte("able"), 2)
+	assert.True(t, replaced)
+	assert.Equal(t, 1, oldValue)
+	_, replaced = tree.Put([]byte(""), 0)
+	assert.False(t, replaced)
+	_, replaced = tree.Put([]byte(""), 3)
+	assert.True(t, replaced)
+	_, replaced = tree.Put(nil, 4)
+	assert.False(t, replaced)
+	assert.Equal(t, 2, tree.Len())
+}
+
+func TestInsertNilValue(t *testing.T) {
+	tree := NewTree()
+	for _, word := range []string{"able", "table", ""} {
+		tree.Insert([]byte(word), nil)
+	}
+	assert.Equal(t, 3, tree.Len())
+	// Overwriting a nil value doesn't add a key
+	for _, word := range []string{"able", "table", ""} {
+		oldValue, ok := tree.Insert([]byte(word), word)
+		assert.True(t, ok)
+		assert.Nil(t, oldValue)
+	}
+	assert.Equal(t, 3, tree.Len())
+	for _, word := range []string{"able", "table", ""} {
+		oldValue, replaced := tree.Put([]byte(word), nil)
+		assert.True(t, replaced)
+		assert.Equal(t, word, oldValue)
+	}
+	assert.Equal(t, 3, tree.Len())
+
+	value, found := tree.Get([]byte("table"))
+	assert.True(t, found)
+	assert.Nil(t, value)
+	_, found = tree.Remove([]byte("table"))
+	assert.True(t, found)
+	assert.Equal(t, 2, tree.Len())
+	_, replaced := tree.Put([]byte("table"), nil)
+	assert.False(t, replaced)
+	assert.Equal(t, 3, tree.Len())
+}
+
+func TestTypedTree(t *testing.T) {
+	tree := New[int]()
+	oldValue, ok := tree.Insert([]byte("able"), 1)
+	assert.True(t, ok)
+	assert.Equal(t, 0, oldValue)
+	tree.Insert([]byte("table"), 2)
+	oldValue, _ = tree.Insert([]byte("able"), 3)
+	assert.Equal(t, 1, oldValue)
+	assert.Equal(t, 2, tree.Len())
+
+	value, found := tree.Get([]byte("able"))
+	assert.True(t, found)
+	assert.Equal(t, 3, value)
+	value, found = tree.Get([]byte("ble"))
+	assert.False(t, found)
+	assert.Equal(t, 0, value)
+
+	matchedKey, value, found := tree.LongestSuffix([]byte("presentable"))
+	assert.True(t, found)
+	assert.Equal(t, "table", string(matchedKey))
+	assert.Equal(t, 2, value)
+
+	sum := 0
+	tree.Walk(func(key []byte, value int) bool {
+		sum += value
+		return false
+	})
+	assert.Equal(t, 5, sum)
+
+	value, found = tree.Remove([]byte("table"))
+	assert.True(t, found)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, tree.Len())
+}
+
+func assertGet(t *testing.T, tree *Tree[interface{}], expectedValue string, expectedFound bool) {
 	value, found := tree.Get([]byte(expectedValue))
 	assert.Equal(t, expectedFound, found)
 	if expectedFound && value != nil {
@@ -87,7 +170,7 @@ func TestGet_Base(t *testing.T) {
 }
 
 // LongestSuffix is mostly like Get, but please notice their slight differnces.
-func assertLongestSuffix(t *testing.T, tree *Tree, key string, expectedFound bool) {
+func assertLongestSuffix(t *testing.T, tree *Tree[interface{}], key string, expectedFound bool) {
 	matchedKey, value, found := tree.LongestSuffix([]byte(key))
 	assert.Equal(t, expectedFound, found)
 	if expectedFound && value != nil {
@@ -95,7 +178,7 @@ func assertLongestSuffix(t *testing.T, tree *Tree, key string, expectedFound boo
 	}
 }
 
-func assertLongestSuffixCheckKey(t *testing.T, tree *Tree, key string,
+func assertLongestSuffixCheckKey(t *testing.T, tree *Tree[interface{}], key string,
 	expectedKey string) {
 
 	matchedKey, value, found := tree.LongestSuffix([]byte(key))
@@ -129,6 +212,117 @@ func TestLongestSuffix_Base(t *testing.T) {
 	assertLongestSuffix(t, tree, "fourth", false)
 }
 
+func TestShortestSuffix(t *testing.T) {
+	tree := NewTree()
+	_, _, found := tree.ShortestSuffix([]byte("banana"))
+	assert.False(t, found)
+
+	tree.Insert([]byte("a.example.com"), "a.example.com")
+	tree.Insert([]byte("example.com"), "example.com")
+	_, _, found = tree.ShortestSuffix([]byte("com"))
+	assert.False(t, found)
+	matchedKey, value, found := tree.ShortestSuffix([]byte("a.example.com"))
+	assert.True(t, found)
+	assert.Equal(t, "example.com", string(matchedKey))
+	assert.Equal(t, "example.com", value.(string))
+
+	tree.Insert([]byte("com"), "com")
+	matchedKey, _, _ = tree.ShortestSuffix([]byte("a.example.com"))
+	assert.Equal(t, "com", string(matchedKey))
+	_, _, found = tree.ShortestSuffix([]byte("org"))
+	assert.False(t, found)
+
+	tree.Insert([]byte(""), "")
+	matchedKey, _, found = tree.ShortestSuffix([]byte("org"))
+	assert.True(t, found)
+	assert.Equal(t, "", string(matchedKey))
+
+	_, _, found = tree.ShortestSuffix(nil)
+	assert.False(t, found)
+}
+
+func TestShortestSuffix_Fixtures(t *testing.T) {
+	lists, tree := getFixtures()
+	for _, key := range append(lists, "unpresentable", "incredible", "a random word", "ble") {
+		expected := ""
+		for _, s := range lists {
+			if strings.HasSuffix(key, s) && (expected == "" || len(s) < len(expected)) {
+				expected = s
+			}
+		}
+		matchedKey, _, found := tree.ShortestSuffix([]byte(key))
+		assert.Equal(t, expected != "", found, "key %s", key)
+		assert.Equal(t, expected, string(matchedKey), "key %s", key)
+	}
+}
+
+func collectSuffixMatches(tree *Tree[interface{}], key string, order MatchOrder) []string {
+	matches := []string{}
+	tree.WalkSuffixMatches([]byte(key), order, func(matchedKey []byte, value interface{}) bool {
+		matches = append(matches, value.(string))
+		return false
+	})
+	return matches
+}
+
+func TestWalkSuffixMatches(t *testing.T) {
+	tree := NewTree()
+	assert.Empty(t, collectSuffixMatches(tree, "a.b.example.com", LongestFirst))
+
+	for _, s := range []string{
+		"com", "example.com", "b.example.com", "a.b.example.com", "c.example.com", "ample.com",
+		"org", "",
+	} {
+		tree.Insert([]byte(s), s)
+	}
+	assert.Equal(t, []string{"a.b.example.com", "b.example.com", "example.com", "ample.com",
+		"com", ""}, collectSuffixMatches(tree, "a.b.example.com", LongestFirst))
+	assert.Equal(t, []string{"", "com", "ample.com", "example.com", "b.example.com",
+		"a.b.example.com"}, collectSuffixMatches(tree, "a.b.example.com", ShortestFirst))
+	assert.Equal(t, []string{"example.com", "ample.com", "com", ""},
+		collectSuffixMatches(tree, "www.example.com", LongestFirst))
+	assert.Equal(t, []string{""}, collectSuffixMatches(tree, "net", LongestFirst))
+
+	count := 0
+	tree.WalkSuffixMatches([]byte("a.b.example.com"), ShortestFirst,
+		func(matchedKey []byte, value interface{}) bool {
+			count++
+			return string(matchedKey) == "ample.com"
+		})
+	assert.Equal(t, 3, count)
+
+	matches := []string{}
+	for matchedKey := range tree.AllSuffixMatches([]byte("a.b.example.com"), LongestFirst) {
+		if len(matchedKey) < len("example.com") {
+			break
+		}
+		matches = append(matches, string(matchedKey))
+	}
+	assert.Equal(t, []string{"a.b.example.com", "b.example.com", "example.com"}, matches)
+}
+
+func TestWalkSuffixMatches_LongestSuffix(t *testing.T) {
+	lists, tree := getFixtures()
+	for _, key := range append(lists, "unpresentable", "incredible", "a random word", "ble") {
+		expected := []string{}
+		for _, s := range lists {
+			if strings.HasSuffix(key, s) {
+				expected = append(expected, s)
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool {
+			return len(expected[i]) > len(expected[j])
+		})
+		matches := collectSuffixMatches(tree, key, LongestFirst)
+		assert.Equal(t, expected, matches, "key %s", key)
+
+		matchedKey, _, found := tree.LongestSuffix([]byte(key))
+		if assert.Equal(t, len(matches) > 0, found) && found {
+			assert.Equal(t, matches[0], string(matchedKey))
+		}
+	}
+}
+
 func TestRemove_EmptyTree(t *testing.T) {
 	tree := NewTree()
 	_, found := tree.Remove([]byte("anything"))
@@ -257,6 +451,75 @@ func TestWalkSuffix_Base(t *testing.T) {
 	assert.Equal(t, 0, count)
 }
 
+func TestAll(t *testing.T) {
+	lists, tree := getFixtures()
+
+	walked := []string{}
+	tree.Walk(func(key []byte, value interface{}) bool {
+		walked = append(walked, string(key))
+		return false
+	})
+	iterated := []string{}
+	for key, value := range tree.All() {
+		assert.Equal(t, string(key), value.(string))
+		iterated = append(iterated, string(key))
+	}
+	assert.Equal(t, walked, iterated)
+	assert.Equal(t, len(lists), len(iterated))
+
+	keys := []string{}
+	for key := range tree.Keys() {
+		keys = append(keys, string(key))
+	}
+	assert.Equal(t, walked, keys)
+
+	values := []string{}
+	for value := range tree.Values() {
+		values = append(values, value.(string))
+	}
+	assert.Equal(t, walked, values)
+
+	count := 0
+	for key := range tree.Keys() {
+		if string(key) == "believable" {
+			break
+		}
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestWithSuffix(t *testing.T) {
+	_, tree := getFixtures()
+
+	for _, suffix := range []string{"", "able", "word", "redible", "nonexist"} {
+		walked := []string{}
+		tree.WalkSuffix([]byte(suffix), func(key []byte, value interface{}) bool {
+			walked = append(walked, string(key))
+			return false
+		})
+		iterated := []string{}
+		for key := range tree.WithSuffix([]byte(suffix)) {
+			iterated = append(iterated, string(key))
+		}
+		assert.Equal(t, walked, iterated, "suffix %s", suffix)
+	}
+
+	count := 0
+	for range tree.WithSuffix([]byte("able")) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, count)
+
+	tree = NewTree()
+	for range tree.WithSuffix([]byte("able")) {
+		assert.Fail(t, "empty tree should not yield anything")
+	}
+}
+
 func TestInvalidInput(t *testing.T) {
 	lists, tree := getFixtures()
 	count := len(lists)
@@ -277,9 +540,299 @@ func TestInvalidInput(t *testing.T) {
 
 	_, _, found = tree.LongestSuffix(nil)
 	assert.False(t, found)
+
+	_, _, found = tree.ShortestSuffix(nil)
+	assert.False(t, found)
+}
+
+func TestInsertCopiesKey(t *testing.T) {
+	tree := New[int]()
+	words := []string{"able", "table", "capable", "fable", "tab", "bale"}
+	// Reuse the buffer like bufio.Scanner does
+	buf := make([]byte, 0, 16)
+	for i, word := range words {
+		buf = append(buf[:0], word...)
+		tree.Insert(buf, i)
+	}
+	copy(buf, "xxxxxxx")
+
+	assert.Equal(t, len(words), tree.Len())
+	for i, word := range words {
+		value, found := tree.Get([]byte(word))
+		assert.True(t, found, word)
+		assert.Equal(t, i, value)
+	}
+	matchedKey, _, _ := tree.LongestSuffix([]byte("uncapable"))
+	assert.Equal(t, "capable", string(matchedKey))
+}
+
+func TestReturnedKeysAreCopies(t *testing.T) {
+	tree := New[int]()
+	tree.Insert([]byte("able"), 1)
+	tree.Insert([]byte("table"), 2)
+
+	matchedKey, _, _ := tree.LongestSuffix([]byte("stable"))
+	copy(matchedKey, "xxxxx")
+	matchedKey, _, _ = tree.ShortestSuffix([]byte("stable"))
+	copy(matchedKey, "xxxx")
+	tree.Walk(func(key []byte, _ int) bool {
+		copy(key, "xxxxx")
+		return false
+	})
+	tree.WalkSuffix([]byte("ble"), func(key []byte, _ int) bool {
+		copy(key, "xxxxx")
+		return false
+	})
+	tree.WalkSuffixMatches([]byte("stable"), LongestFirst, func(key []byte, _ int) bool {
+		copy(key, "xxxxx")
+		return false
+	})
+
+	keys := []string{}
+	tree.Walk(func(key []byte, _ int) bool {
+		keys = append(keys, string(key))
+		return false
+	})
+	assert.Equal(t, []string{"able", "table"}, keys)
+	value, found := tree.Get([]byte("table"))
+	assert.True(t, found)
+	assert.Equal(t, 2, value)
+}
+
+func TestNoCopy(t *testing.T) {
+	tree := NewNoCopy[int]()
+	key := []byte("table")
+	tree.Insert(key, 1)
+	tree.Insert([]byte("able"), 2)
+
+	matchedKey, value, found := tree.LongestSuffix([]byte("stable"))
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.True(t, &key[0] == &matchedKey[0])
+	tree.Walk(func(walked []byte, value int) bool {
+		if value == 1 {
+			assert.True(t, &key[0] == &walked[0])
+		}
+		return false
+	})
+}
+
+func TestInsertExistingKeyKeepsArena(t *testing.T) {
+	tree := New[int]()
+	tree.Insert([]byte("able"), 0)
+	used := len(tree.arena.chunk)
+	for i := 0; i < 100; i++ {
+		tree.Insert([]byte("able"), i)
+	}
+	assert.Equal(t, used, len(tree.arena.chunk))
+	value, _ := tree.Get([]byte("able"))
+	assert.Equal(t, 99, value)
+
+	long := bytes.Repeat([]byte("a"), arenaMaxKeySize)
+	tree.Insert(long, 1)
+	assert.Equal(t, used, len(tree.arena.chunk))
+	_, found := tree.Get(long)
+	assert.True(t, found)
+}
+
+// Return a random key made of letters, whose length is between minLen and maxLen.
+func randomKey(r *rand.Rand, letters []byte, minLen, maxLen int) []byte {
+	key := make([]byte, minLen+r.Intn(maxLen-minLen+1))
+	for i := range key {
+		key[i] = letters[r.Intn(len(letters))]
+	}
+	return key
+}
+
+func assertNodeLeavesNum[V any](t *testing.T, node *_Node[V]) int {
+	leavesNum := 0
+	for _, edge := range node.edges {
+		switch point := edge.point.(type) {
+		case *_Leaf[V]:
+			leavesNum++
+		case *_Node[V]:
+			leavesNum += assertNodeLeavesNum(t, point)
+		}
+	}
+	assert.Equal(t, leavesNum, node.leavesNum)
+	return leavesNum
+}
+
+func TestCountSuffix(t *testing.T) {
+	lists, tree := getFixtures()
+	assert.Equal(t, len(lists), tree.CountSuffix(nil))
+	assert.Equal(t, 0, NewTree().CountSuffix([]byte("able")))
+
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	for i := 0; i < 2000; i++ {
+		key := randomKey(r, letters, 0, 7)
+		if r.Intn(3) == 0 {
+			tree.Remove(key)
+		} else {
+			tree.Insert(key, string(key))
+		}
+	}
+	assertNodeLeavesNum(t, tree.root)
+	assert.Equal(t, tree.Len(), tree.root.leavesNum)
+
+	for i := 0; i < 200; i++ {
+		suffix := randomKey(r, letters, 1, 6)
+		walked := 0
+		tree.WalkSuffix(suffix, func(key []byte, value interface{}) bool {
+			walked++
+			return false
+		})
+		assert.Equal(t, walked, tree.CountSuffix(suffix), string(suffix))
+	}
+}
+
+func TestRemoveSuffix(t *testing.T) {
+	tree := New[string]()
+	assert.Equal(t, 0, tree.RemoveSuffix([]byte("able")))
+	for _, word := range []string{"", "able", "table", "stable", "cable", "bale", "ble"} {
+		tree.InsertString(word, word)
+	}
+
+	removed := []string{}
+	n := tree.RemoveSuffixFunc([]byte("table"), func(key []byte, value string) {
+		assert.Equal(t, string(key), value)
+		removed = append(removed, value)
+	})
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"table", "stable"}, removed)
+	assert.Equal(t, 5, tree.Len())
+
+	assert.Equal(t, 0, tree.RemoveSuffix([]byte("xble")))
+	assert.Equal(t, 2, tree.RemoveSuffix([]byte("able")))
+	assert.Equal(t, 3, tree.Len())
+	_, found := tree.GetString("able")
+	assert.False(t, found)
+	value, found := tree.GetString("ble")
+	assert.True(t, found)
+	assert.Equal(t, "ble", value)
+
+	assert.Equal(t, 3, tree.RemoveSuffix(nil))
+	assert.Equal(t, 0, tree.Len())
+	_, found = tree.GetString("")
+	assert.False(t, found)
+}
+
+func TestRemoveSuffix_SameAsRemove(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	for turn := 0; turn < 100; turn++ {
+		tree := New[string]()
+		expected := New[string]()
+		for i := 0; i < 64; i++ {
+			key := randomKey(r, letters, 0, 7)
+			tree.Insert(key, string(key))
+			expected.Insert(key, string(key))
+		}
+		suffix := randomKey(r, letters, 0, 3)
+		keys := [][]byte{}
+		expected.WalkSuffix(suffix, func(key []byte, _ string) bool {
+			keys = append(keys, key)
+			return false
+		})
+		for _, key := range keys {
+			expected.Remove(key)
+		}
+
+		assert.Equal(t, len(keys), tree.RemoveSuffix(suffix))
+		assertSameStructure(t, expected, tree)
+		for _, key := range keys {
+			_, found := tree.Get(key)
+			assert.False(t, found)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tree := New[int]()
+	incr := func(oldValue int, exists bool) (int, bool) {
+		return oldValue + 1, true
+	}
+	for _, word := range []string{"able", "table", "able", "", "able", ""} {
+		tree.Update([]byte(word), incr)
+	}
+	assert.Equal(t, 3, tree.Len())
+	value, _ := tree.Get([]byte("able"))
+	assert.Equal(t, 3, value)
+	value, _ = tree.Get([]byte(""))
+	assert.Equal(t, 2, value)
+	value, exists := tree.Update([]byte("table"), incr)
+	assert.True(t, exists)
+	assert.Equal(t, 2, value)
+
+	remove := func(oldValue int, exists bool) (int, bool) {
+		return 0, false
+	}
+	_, exists = tree.Update([]byte("able"), remove)
+	assert.False(t, exists)
+	_, found := tree.Get([]byte("able"))
+	assert.False(t, found)
+	assert.Equal(t, 2, tree.Len())
+	// Not inserted
+	called := false
+	tree.Update([]byte("stable"), func(oldValue int, exists bool) (int, bool) {
+		assert.False(t, exists)
+		called = true
+		return 0, false
+	})
+	assert.True(t, called)
+	assert.Equal(t, 2, tree.Len())
+	_, found = tree.Get([]byte("stable"))
+	assert.False(t, found)
+
+	_, exists = tree.Update(nil, incr)
+	assert.False(t, exists)
+	assertNodeLeavesNum(t, tree.root)
+}
+
+func TestUpdate_SameAsInsertAndRemove(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	tree := New[string]()
+	expected := New[string]()
+	for i := 0; i < 4096; i++ {
+		key := randomKey(r, letters, 0, 7)
+		keep := r.Intn(3) != 0
+		tree.Update(key, func(oldValue string, exists bool) (string, bool) {
+			_, found := expected.Get(key)
+			assert.Equal(t, found, exists)
+			return string(key), keep
+		})
+		if keep {
+			expected.Insert(key, string(key))
+		} else {
+			expected.Remove(key)
+		}
+		if i%256 == 0 {
+			assertSameStructure(t, expected, tree)
+		}
+	}
+	assertSameStructure(t, expected, tree)
+}
+
+func TestGetOrInsert(t *testing.T) {
+	tree := New[int]()
+	actual, loaded := tree.GetOrInsert([]byte("able"), 1)
+	assert.False(t, loaded)
+	assert.Equal(t, 1, actual)
+	actual, loaded = tree.GetOrInsert([]byte("able"), 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, actual)
+
+	assert.True(t, tree.InsertIfAbsent([]byte("table"), 3))
+	assert.False(t, tree.InsertIfAbsent([]byte("table"), 4))
+	assert.False(t, tree.InsertIfAbsent(nil, 4))
+	value, _ := tree.Get([]byte("table"))
+	assert.Equal(t, 3, value)
+	assert.Equal(t, 2, tree.Len())
 }
 
-func dumpTestData(wordRef map[string]bool, tree *Tree, ops []string, errMsg string) {
+func dumpTestData(wordRef map[string]bool, tree *Tree[interface{}], ops []string, errMsg string) {
 	opDumpFile, _ := ioutil.TempFile("", "suffix_test_op_dump_")
 	defer opDumpFile.Close()
 	for _, op := range ops {
@@ -319,7 +872,7 @@ func dumpTestData(wordRef map[string]bool, tree *Tree, ops []string, errMsg stri
 	println("Also dump final node status to", nodeDumpFile.Name())
 }
 
-func checkLabelOrder(tree *Tree) (string, bool) {
+func checkLabelOrder(tree *Tree[interface{}]) (string, bool) {
 	var preLabelLen int
 	var msg string
 	inOrder := true
@@ -364,7 +917,7 @@ func TestAlhoc(t *testing.T) {
 	testTurns := 0
 	testEnd := time.NewTimer(30 * time.Second)
 	var errMsg string
-	var tree *Tree
+	var tree *Tree[interface{}]
 	defer func() {
 		if r := recover(); r != nil {
 			errMsg = fmt.Sprintf("Panic happened: %v", r)
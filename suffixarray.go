@@ -0,0 +1,306 @@
+package suffix
+
+import (
+	"bytes"
+	"sort"
+)
+
+// SuffixArray is a read-only index of keys, which finds every key containing a pattern like
+// SubstringIndex. It is built with SA-IS in linear time, and takes less memory: the keys are
+// concatenated into one text, plus two int32 per byte for the suffix array and LCP array.
+type SuffixArray struct {
+	// The keys are concatenated without separator
+	text []byte
+	// The start of each key in text, and the end of the last key
+	keyStarts []int32
+	// The start positions of the suffixes of text in lexicographical order
+	sa []int32
+	// lcp[i] is the length of the longest common prefix of the suffixes at sa[i-1] and sa[i],
+	// within their keys. lcp[0] is 0.
+	lcp []int32
+}
+
+// NewSuffixArray creates a SuffixArray of a single text, whose id is 0.
+func NewSuffixArray(text []byte) *SuffixArray {
+	return NewSuffixArrayFromKeys([][]byte{text})
+}
+
+// NewSuffixArrayFromKeys creates a SuffixArray of given keys. The id of each key is its index.
+// The keys are copied.
+func NewSuffixArrayFromKeys(keys [][]byte) *SuffixArray {
+	textLen := 0
+	for _, key := range keys {
+		textLen += len(key)
+	}
+	arr := &SuffixArray{
+		text:      make([]byte, 0, textLen),
+		keyStarts: make([]int32, 0, len(keys)+1),
+	}
+	for _, key := range keys {
+		arr.keyStarts = append(arr.keyStarts, int32(len(arr.text)))
+		arr.text = append(arr.text, key...)
+	}
+	arr.keyStarts = append(arr.keyStarts, int32(len(arr.text)))
+	arr.sa = sais(arr.text, 256)
+	arr.lcp = arr.buildLCP()
+	return arr
+}
+
+// SuffixArray creates a SuffixArray of the keys in the tree. The ids of keys are assigned
+// in the order of Walk.
+func (tree *Tree[V]) SuffixArray() *SuffixArray {
+	keys := make([][]byte, 0, tree.leavesNum)
+	stop := false
+	tree.root.walk(func(key []byte, _ V) bool {
+		keys = append(keys, key)
+		return false
+	}, &stop)
+	return NewSuffixArrayFromKeys(keys)
+}
+
+// Tree creates a Tree of the keys in the SuffixArray, whose values are the ids of keys.
+// If a key is duplicate, the larger id is kept.
+func (arr *SuffixArray) Tree() *Tree[int] {
+	tree := New[int]()
+	for id := 0; id < arr.Len(); id++ {
+		tree.insert(arr.Key(id), id)
+	}
+	return tree
+}
+
+// Len returns the number of keys.
+func (arr *SuffixArray) Len() int {
+	return len(arr.keyStarts) - 1
+}
+
+// Key returns a copy of the key with given id.
+func (arr *SuffixArray) Key(id int) []byte {
+	return append([]byte{}, arr.text[arr.keyStarts[id]:arr.keyStarts[id+1]]...)
+}
+
+// Keys returns a copy of all the keys, in the order of ids.
+func (arr *SuffixArray) Keys() [][]byte {
+	keys := make([][]byte, arr.Len())
+	for id := range keys {
+		keys[id] = arr.Key(id)
+	}
+	return keys
+}
+
+// Suffixes returns the suffix array: the start positions of the suffixes of the concatenated
+// keys in lexicographical order. The returned slice must not be modified.
+func (arr *SuffixArray) Suffixes() []int32 {
+	return arr.sa
+}
+
+// LCP returns the LCP array. Its i-th element is the length of the longest common prefix of
+// the (i-1)-th and i-th suffixes, within their keys. The returned slice must not be modified.
+func (arr *SuffixArray) LCP() []int32 {
+	return arr.lcp
+}
+
+// Return the id of the key which contains the given position of text.
+func (arr *SuffixArray) keyOf(pos int32) int {
+	return sort.Search(arr.Len(), func(id int) bool {
+		return arr.keyStarts[id+1] > pos
+	})
+}
+
+// Contains returns whether any key contains the pattern.
+func (arr *SuffixArray) Contains(pattern []byte) bool {
+	return arr.Len() > 0 && (len(pattern) == 0 || len(arr.Lookup(pattern)) > 0)
+}
+
+// Lookup returns all the positions where the pattern occurs in the keys, ordered by the key
+// id and then the offset. The empty pattern occurs at every offset of each key, including
+// the one after the last byte.
+func (arr *SuffixArray) Lookup(pattern []byte) []Occurrence {
+	occurrences := []Occurrence{}
+	if len(pattern) == 0 {
+		for id := 0; id < arr.Len(); id++ {
+			for offset := int32(0); offset <= arr.keyStarts[id+1]-arr.keyStarts[id]; offset++ {
+				occurrences = append(occurrences, Occurrence{Key: id, Offset: int(offset)})
+			}
+		}
+		return occurrences
+	}
+
+	patternLen := int32(len(pattern))
+	prefix := func(i int) []byte {
+		start := arr.sa[i]
+		end := start + patternLen
+		if end > int32(len(arr.text)) {
+			end = int32(len(arr.text))
+		}
+		return arr.text[start:end]
+	}
+	// The suffixes starting with pattern are adjacent
+	lo := sort.Search(len(arr.sa), func(i int) bool {
+		return bytes.Compare(prefix(i), pattern) >= 0
+	})
+	hi := sort.Search(len(arr.sa), func(i int) bool {
+		return bytes.Compare(prefix(i), pattern) > 0
+	})
+	for _, pos := range arr.sa[lo:hi] {
+		id := arr.keyOf(pos)
+		// As the keys are not separated, skip the match across keys
+		if pos+patternLen <= arr.keyStarts[id+1] {
+			occurrences = append(occurrences, Occurrence{Key: id, Offset: int(pos - arr.keyStarts[id])})
+		}
+	}
+	sortOccurrences(occurrences)
+	return occurrences
+}
+
+// Build the LCP array with Kasai's algorithm, and then clip it to the boundaries of keys.
+func (arr *SuffixArray) buildLCP() []int32 {
+	n := len(arr.text)
+	lcp := make([]int32, n)
+	rank := make([]int32, n)
+	for i, pos := range arr.sa {
+		rank[pos] = int32(i)
+	}
+	h := 0
+	for i := 0; i < n; i++ {
+		if rank[i] == 0 {
+			h = 0
+			continue
+		}
+		j := int(arr.sa[rank[i]-1])
+		for i+h < n && j+h < n && arr.text[i+h] == arr.text[j+h] {
+			h++
+		}
+		lcp[rank[i]] = int32(h)
+		// The suffix at i+1 shares at least h-1 bytes with its predecessor
+		if h > 0 {
+			h--
+		}
+	}
+	if arr.Len() > 1 {
+		rest := func(pos int32) int32 {
+			return arr.keyStarts[arr.keyOf(pos)+1] - pos
+		}
+		for i := 1; i < n; i++ {
+			lcp[i] = min(lcp[i], rest(arr.sa[i]), rest(arr.sa[i-1]))
+		}
+	}
+	return lcp
+}
+
+// Return the suffix array of s with SA-IS, the symbols of s are in [0, alphabetSize).
+// A virtual sentinel, which is smaller than any symbol, is assumed at the end of s.
+func sais[T byte | int32](s []T, alphabetSize int) []int32 {
+	n := len(s)
+	sa := make([]int32, n)
+	if n <= 1 {
+		return sa
+	}
+
+	// A suffix is S type if it is smaller than the next suffix, otherwise L type.
+	// The last suffix is larger than the sentinel.
+	isS := make([]bool, n)
+	for i := n - 2; i >= 0; i-- {
+		isS[i] = s[i] < s[i+1] || (s[i] == s[i+1] && isS[i+1])
+	}
+	isLMS := func(i int) bool {
+		return i > 0 && i < n && isS[i] && !isS[i-1]
+	}
+
+	// buckets[c] is the start of the bucket of symbol c, and buckets[c+1] is its end
+	buckets := make([]int32, alphabetSize+1)
+	for _, c := range s {
+		buckets[int(c)+1]++
+	}
+	for c := 1; c <= alphabetSize; c++ {
+		buckets[c] += buckets[c-1]
+	}
+	heads := make([]int32, alphabetSize)
+	tails := make([]int32, alphabetSize)
+
+	// Sort all the suffixes from the LMS suffixes sorted in given order
+	induce := func(lms []int32) {
+		for i := range sa {
+			sa[i] = -1
+		}
+		copy(tails, buckets[1:])
+		for i := len(lms) - 1; i >= 0; i-- {
+			c := s[lms[i]]
+			tails[c]--
+			sa[tails[c]] = lms[i]
+		}
+		// The L type suffixes, starting from the one before the sentinel
+		copy(heads, buckets)
+		c := s[n-1]
+		sa[heads[c]] = int32(n - 1)
+		heads[c]++
+		for i := 0; i < n; i++ {
+			if j := sa[i] - 1; j >= 0 && !isS[j] {
+				c := s[j]
+				sa[heads[c]] = j
+				heads[c]++
+			}
+		}
+		// The S type suffixes, including the LMS ones
+		copy(tails, buckets[1:])
+		for i := n - 1; i >= 0; i-- {
+			if j := sa[i] - 1; j >= 0 && isS[j] {
+				c := s[j]
+				tails[c]--
+				sa[tails[c]] = j
+			}
+		}
+	}
+
+	lms := []int32{}
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			lms = append(lms, int32(i))
+		}
+	}
+	// Sort the LMS substrings
+	induce(lms)
+	sortedLMS := make([]int32, 0, len(lms))
+	for _, pos := range sa {
+		if isLMS(int(pos)) {
+			sortedLMS = append(sortedLMS, pos)
+		}
+	}
+
+	// Name the LMS substrings by their order, equal substrings have the same name
+	lmsEqual := func(a, b int) bool {
+		for i := 0; ; i++ {
+			if a+i == n || b+i == n {
+				// Only one substring could reach the sentinel
+				return false
+			}
+			if s[a+i] != s[b+i] || isS[a+i] != isS[b+i] {
+				return false
+			}
+			if i > 0 && (isLMS(a+i) || isLMS(b+i)) {
+				return isLMS(a+i) && isLMS(b+i)
+			}
+		}
+	}
+	names := make([]int32, n)
+	name := int32(0)
+	for i, pos := range sortedLMS {
+		if i > 0 && !lmsEqual(int(sortedLMS[i-1]), int(pos)) {
+			name++
+		}
+		names[pos] = name
+	}
+
+	if int(name)+1 < len(lms) {
+		// Sort the LMS suffixes by the suffix array of the names
+		reduced := make([]int32, len(lms))
+		for i, pos := range lms {
+			reduced[i] = names[pos]
+		}
+		for i, j := range sais(reduced, int(name)+1) {
+			sortedLMS[i] = lms[j]
+		}
+	}
+	// Otherwise the LMS substrings are unique, so are sorted as the LMS suffixes
+	induce(sortedLMS)
+	return sa
+}
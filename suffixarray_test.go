@@ -0,0 +1,139 @@
+package suffix
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bruteForceSuffixArray(text []byte) []int32 {
+	sa := make([]int32, len(text))
+	for i := range sa {
+		sa[i] = int32(i)
+	}
+	sort.Slice(sa, func(i, j int) bool {
+		return bytes.Compare(text[sa[i]:], text[sa[j]:]) < 0
+	})
+	return sa
+}
+
+func bruteForceLCP(keys [][]byte, arr *SuffixArray) []int32 {
+	// Map the positions of text back to the suffixes of keys
+	suffixes := [][]byte{}
+	for _, key := range keys {
+		for i := range key {
+			suffixes = append(suffixes, key[i:])
+		}
+	}
+	lcp := make([]int32, len(suffixes))
+	sa := arr.Suffixes()
+	for i := 1; i < len(sa); i++ {
+		a, b := suffixes[sa[i-1]], suffixes[sa[i]]
+		for int(lcp[i]) < len(a) && int(lcp[i]) < len(b) && a[lcp[i]] == b[lcp[i]] {
+			lcp[i]++
+		}
+	}
+	return lcp
+}
+
+func TestSuffixArray(t *testing.T) {
+	arr := NewSuffixArray([]byte("banana"))
+	assert.Equal(t, []int32{5, 3, 1, 0, 4, 2}, arr.Suffixes())
+	assert.Equal(t, []int32{0, 1, 3, 0, 0, 2}, arr.LCP())
+	assert.Equal(t, 1, arr.Len())
+	assert.Equal(t, []Occurrence{{0, 1}, {0, 3}}, arr.Lookup([]byte("ana")))
+
+	arr = NewSuffixArrayFromKeys([][]byte{[]byte("banana"), []byte("bandana"), []byte("")})
+	assert.Equal(t, 3, arr.Len())
+	assert.Equal(t, "bandana", string(arr.Key(1)))
+	assert.Equal(t, "", string(arr.Key(2)))
+	assert.True(t, arr.Contains([]byte("dan")))
+	assert.True(t, arr.Contains([]byte("")))
+	// "ab" only occurs across the boundary of keys
+	assert.False(t, arr.Contains([]byte("ab")))
+	assert.False(t, arr.Contains([]byte("bananas")))
+	assert.Equal(t, []Occurrence{{0, 1}, {0, 3}, {1, 4}}, arr.Lookup([]byte("ana")))
+	assert.Equal(t, []Occurrence{}, arr.Lookup([]byte("x")))
+	// Every offset, including the one after the last byte
+	assert.Equal(t, 7+8+1, len(arr.Lookup(nil)))
+
+	empty := NewSuffixArrayFromKeys(nil)
+	assert.Equal(t, 0, empty.Len())
+	assert.False(t, empty.Contains(nil))
+	assert.Equal(t, []Occurrence{}, empty.Lookup([]byte("a")))
+}
+
+func TestSuffixArray_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for turn := 0; turn < 100; turn++ {
+		// Small alphabets make many equal LMS substrings, so SA-IS recurses
+		letters := []byte("abcd")[:1+r.Intn(4)]
+
+		text := randomKey(r, letters, 0, 199)
+		assert.Equal(t, bruteForceSuffixArray(text), NewSuffixArray(text).Suffixes(), string(text))
+
+		keys := [][]byte{}
+		for i := 0; i < 1+r.Intn(8); i++ {
+			keys = append(keys, randomKey(r, letters, 0, 15))
+		}
+		arr := NewSuffixArrayFromKeys(keys)
+		assert.Equal(t, keys, arr.Keys())
+		assert.Equal(t, bruteForceLCP(keys, arr), arr.LCP())
+		for j := 0; j < 16; j++ {
+			pattern := randomKey(r, letters, 1, 5)
+			expected := bruteForceOccurrences(keys, pattern)
+			assert.Equal(t, expected, arr.Lookup(pattern), string(pattern))
+			assert.Equal(t, len(expected) > 0, arr.Contains(pattern), string(pattern))
+		}
+	}
+}
+
+func TestSuffixArrayAndTree(t *testing.T) {
+	tree := New[int]()
+	keys := []string{"index.html", "main.css", "main.js", "vendor.js"}
+	for i, key := range keys {
+		tree.Insert([]byte(key), i)
+	}
+	arr := tree.SuffixArray()
+	assert.Equal(t, len(keys), arr.Len())
+	seen := []string{}
+	tree.Walk(func(key []byte, _ int) bool {
+		seen = append(seen, string(key))
+		return false
+	})
+	for id, key := range seen {
+		assert.Equal(t, key, string(arr.Key(id)))
+	}
+
+	matched := []string{}
+	for _, occurrence := range arr.Lookup([]byte("ain")) {
+		matched = append(matched, string(arr.Key(occurrence.Key)))
+	}
+	sort.Strings(matched)
+	assert.Equal(t, []string{"main.css", "main.js"}, matched)
+
+	back := arr.Tree()
+	assert.Equal(t, tree.Len(), back.Len())
+	for id, key := range seen {
+		value, found := back.Get([]byte(key))
+		assert.True(t, found)
+		assert.Equal(t, id, value)
+	}
+
+	assert.Equal(t, 0, New[int]().SuffixArray().Len())
+}
+
+func BenchmarkNewSuffixArray(b *testing.B) {
+	text := make([]byte, 1<<16)
+	for i := range text {
+		text[i] = byte('a' + rand.Intn(4))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewSuffixArray(text)
+	}
+}
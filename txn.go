@@ -0,0 +1,100 @@
+package suffix
+
+// Add the nodes along the path of given key to owned.
+func (node *_Node[V]) markPath(key []byte, owned map[*_Node[V]]struct{}) {
+	owned[node] = struct{}{}
+	if edge := matchEdge(node, key); edge != nil {
+		if child, ok := edge.point.(*_Node[V]); ok {
+			child.markPath(key[:len(key)-len(edge.label)], owned)
+		}
+	}
+}
+
+// Txn batches mutations to an ImmutableTree. The mutations are private to the transaction
+// until Commit is called, and each node is copied at most once in a transaction.
+// A Txn is not safe for concurrent use.
+type Txn[V any] struct {
+	tree Tree[V]
+	// Nodes which are copied or created by this transaction, so they can be modified in place
+	owned map[*_Node[V]]struct{}
+}
+
+// Txn starts a transaction based on the tree. The tree itself is not modified.
+func (t *ImmutableTree[V]) Txn() *Txn[V] {
+	return &Txn[V]{
+		tree:  t.tree,
+		owned: map[*_Node[V]]struct{}{},
+	}
+}
+
+// Insert given key and value in the transaction. Return the previous value and a boolean to
+// indicate whether the insertion is successful.
+func (txn *Txn[V]) Insert(key []byte, value V) (oldValue V, ok bool) {
+	if key == nil {
+		return oldValue, false
+	}
+	txn.tree.root = txn.tree.root.clonePath(key, txn.owned)
+	oldValue, ok = txn.tree.Insert(key, value)
+	// The nodes created by insertion are private too
+	txn.tree.root.markPath(key, txn.owned)
+	return oldValue, ok
+}
+
+// Update the value of key with the value decided by f in the transaction. See Tree.Update.
+func (txn *Txn[V]) Update(key []byte, f func(oldValue V, exists bool) (newValue V, keep bool)) (
+	value V, exists bool) {
+
+	if key == nil {
+		return value, false
+	}
+	txn.tree.root = txn.tree.root.clonePath(key, txn.owned)
+	value, exists = txn.tree.Update(key, f)
+	txn.tree.root.markPath(key, txn.owned)
+	return value, exists
+}
+
+// Remove given key in the transaction. Return the removed value and a boolean to
+// indicate whether the key is found.
+func (txn *Txn[V]) Remove(key []byte) (oldValue V, found bool) {
+	if key == nil || len(txn.tree.root.edges) == 0 {
+		return oldValue, false
+	}
+	txn.tree.root = txn.tree.root.clonePath(key, txn.owned)
+	return txn.tree.Remove(key)
+}
+
+// RemoveSuffix removes the keys which have given suffix in the transaction. Return the
+// number of removed keys.
+func (txn *Txn[V]) RemoveSuffix(suffix []byte) (removed int) {
+	if txn.tree.CountSuffix(suffix) == 0 {
+		return 0
+	}
+	txn.tree.root = txn.tree.root.clonePath(suffix, txn.owned)
+	return txn.tree.RemoveSuffix(suffix)
+}
+
+// Get returns the value of given key in the transaction, and a boolean to indicate
+// whether the value is found.
+func (txn *Txn[V]) Get(key []byte) (value V, found bool) {
+	return txn.tree.Get(key)
+}
+
+// Len returns the number of keys in the transaction.
+func (txn *Txn[V]) Len() int {
+	return txn.tree.Len()
+}
+
+// Commit returns a new ImmutableTree with all the mutations in the transaction.
+// The transaction could still be used after Commit, and the following mutations
+// don't affect the committed tree.
+func (txn *Txn[V]) Commit() *ImmutableTree[V] {
+	// The committed nodes become shared
+	txn.owned = map[*_Node[V]]struct{}{}
+	committed := &ImmutableTree[V]{
+		tree: txn.tree,
+	}
+	// The arena is kept by the transaction, so the transactions based on the committed
+	// tree allocate keys in their own arenas.
+	committed.tree.arena = _Arena{}
+	return committed
+}
@@ -0,0 +1,121 @@
+package suffix
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxn_Base(t *testing.T) {
+	base := NewImmutableTree[string]()
+	base, _, _ = base.Insert([]byte("able"), "able")
+
+	txn := base.Txn()
+	_, ok := txn.Insert([]byte("table"), "table")
+	assert.True(t, ok)
+	oldValue, _ := txn.Insert([]byte("able"), "ABLE")
+	assert.Equal(t, "able", oldValue)
+	_, ok = txn.Insert(nil, "")
+	assert.False(t, ok)
+	value, found := txn.Get([]byte("table"))
+	assert.True(t, found)
+	assert.Equal(t, "table", value)
+	assert.Equal(t, 2, txn.Len())
+
+	// Not visible before commit
+	assertTreeContent(t, base, map[string]bool{"able": true})
+
+	committed := txn.Commit()
+	assert.Equal(t, 2, committed.Len())
+	value, _ = committed.Get([]byte("able"))
+	assert.Equal(t, "ABLE", value)
+
+	// Mutations after commit don't affect the committed tree
+	oldValue, found = txn.Remove([]byte("table"))
+	assert.True(t, found)
+	assert.Equal(t, "table", oldValue)
+	txn.Insert([]byte("able"), "able")
+	_, found = txn.Remove([]byte("unknown"))
+	assert.False(t, found)
+	_, found = txn.Remove(nil)
+	assert.False(t, found)
+	assert.Equal(t, 1, txn.Len())
+	assert.Equal(t, 2, committed.Len())
+	value, _ = committed.Get([]byte("able"))
+	assert.Equal(t, "ABLE", value)
+	assertTreeContent(t, txn.Commit(), map[string]bool{"able": true})
+}
+
+func TestTxn_CopyOnce(t *testing.T) {
+	base := NewImmutableTree[string]()
+	for _, s := range []string{"able", "table", "word"} {
+		base, _, _ = base.Insert([]byte(s), s)
+	}
+	txn := base.Txn()
+	txn.Insert([]byte("presentable"), "presentable")
+	root := txn.tree.root
+	assert.False(t, root == base.tree.root)
+
+	txn.Insert([]byte("sword"), "sword")
+	txn.Insert([]byte("credible"), "credible")
+	txn.Remove([]byte("word"))
+	assert.True(t, root == txn.tree.root)
+	for node := range txn.owned {
+		assert.False(t, node == base.tree.root)
+	}
+}
+
+func TestTxn_Update(t *testing.T) {
+	v0 := NewImmutableTree[string]()
+	v0, _, _ = v0.Insert([]byte("able"), "able")
+	txn := v0.Txn()
+	value, exists := txn.Update([]byte("table"), func(oldValue string, exists bool) (string, bool) {
+		assert.False(t, exists)
+		return "table", true
+	})
+	assert.True(t, exists)
+	assert.Equal(t, "table", value)
+	txn.Update([]byte("able"), func(oldValue string, exists bool) (string, bool) {
+		assert.True(t, exists)
+		return "", false
+	})
+	assertTreeContent(t, txn.Commit(), map[string]bool{"table": true})
+	assertTreeContent(t, v0, map[string]bool{"able": true})
+}
+
+func TestTxn_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	letters := []byte("abc")
+	words := []string{}
+	for i := 0; i < 128; i++ {
+		words = append(words, string(randomKey(r, letters, 0, 7)))
+	}
+
+	tree := NewImmutableTree[string]()
+	expected := map[string]bool{}
+	versions := []*ImmutableTree[string]{tree}
+	contents := []map[string]bool{{}}
+	txn := tree.Txn()
+	for i := 0; i < 2048; i++ {
+		word := words[r.Intn(len(words))]
+		if r.Intn(3) == 0 {
+			txn.Remove([]byte(word))
+			delete(expected, word)
+		} else {
+			txn.Insert([]byte(word), word)
+			expected[word] = true
+		}
+		if i%128 == 0 {
+			versions = append(versions, txn.Commit())
+			content := map[string]bool{}
+			for k := range expected {
+				content[k] = true
+			}
+			contents = append(contents, content)
+		}
+	}
+	for i, tree := range versions {
+		assertTreeContent(t, tree, contents[i])
+	}
+}